@@ -8,6 +8,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+
+	"github.com/jedib0t/go-pretty/table"
 
 	"github.com/carlisia/ghinfo/github"
 )
@@ -19,77 +23,238 @@ type StatsReport interface {
 	Name() string
 }
 
+// Exporter is implemented by reports that can be written out as
+// data files, besides being printed.
+type Exporter interface {
+	WriteJSON(io.Writer) error
+	WriteCSV(io.Writer) error
+}
+
+// Sortable is implemented by reports that can be sorted again once they
+// are run, from the aggregates they keep, without fetching anything.
+type Sortable interface {
+	// Columns returns the columns the report can be sorted by.
+	Columns() []string
+	// SortedBy returns the column, and the order, the report is sorted by.
+	SortedBy() (column string, asc bool)
+	Sort(column string, asc bool) error
+}
+
+// Tabular is implemented by reports printed as a single table.
+type Tabular interface {
+	// Table returns the table printed by PrintStats, to be rendered
+	// elsewhere.
+	Table() table.Writer
+}
+
+// Recorder is implemented by reports that keep the repositories they were
+// built from, e.g. to save them as a snapshot.
+type Recorder interface {
+	StatsReport
+	Results() Results
+}
+
+// Results are the repositories a report was built from.
+type Results struct {
+	ReportName string
+	ReportType string
+	Options    ParamOptions
+	// Listed are all the repositories found in the ID range.
+	Listed []github.Repos
+	// Enriched are the listed repositories that could be enriched with
+	// their star count and license.
+	Enriched []github.Repos
+}
+
 type ParamOptions struct {
 	Column string
 	Asc    bool
 	MaxID  int
 	Since  int
+	// Top limits the printed rows to the first N, once sorted.
+	// Only used by reports where it makes sense, e.g. owners.
+	Top int
+	// Concurrency is the number of repositories enriched at the same
+	// time, DefaultConcurrency when not set.
+	Concurrency int
+	// Chart draws bar charts of the star buckets and licenses of the
+	// repositories, and a histogram of their stars, below the table
+	// printed by PrintStats. Not used by sampled reports, which don't
+	// keep the repositories.
+	Chart bool
 }
 
 type report struct {
 	name             string
+	reportType       string
 	query            github.Query
 	repoCount        int
 	aggregatedErrors []error
+	listed           []github.Repos
+	records          []repoRecord
+	stats            PipelineStats
+	// progress is the func the progress of the run is reported to,
+	// printed when nil.
+	progress func(Progress)
+}
+
+func (r report) results(opts ParamOptions) Results {
+	enriched := make([]github.Repos, len(r.records))
+	for i := range r.records {
+		enriched[i] = r.records[i].repo
+	}
+
+	return Results{
+		ReportName: r.name,
+		ReportType: r.reportType,
+		Options:    opts,
+		Listed:     r.listed,
+		Enriched:   enriched,
+	}
 }
 
 const (
 	StarGazersReportType = "1"
 	LicenseReportType    = "2"
+	OwnerReportType      = "3"
+	CrossTabReportType   = "4"
 
 	starGazersReportName   = "StarGazers Report"
 	licenseTypesReportName = "License Types Report"
+	ownersReportName       = "Owners Report"
+	crossTabReportName     = "License Family by Star Bucket Report"
 
-	bucketCol  = "bucket"
-	starCol    = "stars"
-	repoCol    = "repos"
-	licenseCol = "license type"
+	// Column names, which can also be used as ParamOptions.Column
+	// in place of the numbered options.
+	bucketCol    = "bucket"
+	starCol      = "stars"
+	repoCol      = "repos"
+	licenseCol   = "license type"
+	ownerCol     = "owner"
+	ownerTypeCol = "type"
 )
 
-// TODO: Have a robust CLI that can handle these
-// options with flags.
+// ReportTypes maps the report names used on the command line
+// to their report type.
+var ReportTypes = map[string]string{
+	"stars":    StarGazersReportType,
+	"licenses": LicenseReportType,
+	"owners":   OwnerReportType,
+	"crosstab": CrossTabReportType,
+}
+
 func columnOptions() func(string, string) string {
 	bucket := map[string]string{
-		"1": bucketCol,
-		"2": repoCol,
-		"3": starCol,
+		"1":       bucketCol,
+		"2":       repoCol,
+		"3":       starCol,
+		bucketCol: bucketCol,
+		repoCol:   repoCol,
+		starCol:   starCol,
 	}
 
 	license := map[string]string{
-		"1": bucketCol,
-		"2": repoCol,
+		"1":        licenseCol,
+		"2":        repoCol,
+		"license":  licenseCol,
+		licenseCol: licenseCol,
+		repoCol:    repoCol,
+	}
+
+	owner := map[string]string{
+		"1":          ownerCol,
+		"2":          ownerTypeCol,
+		"3":          repoCol,
+		"4":          starCol,
+		ownerCol:     ownerCol,
+		ownerTypeCol: ownerTypeCol,
+		repoCol:      repoCol,
+		starCol:      starCol,
+	}
+
+	crossTab := map[string]string{
+		"1":       bucketCol,
+		"2":       repoCol,
+		bucketCol: bucketCol,
+		repoCol:   repoCol,
 	}
 
 	return func(reportType, key string) string {
 		switch reportType {
 		case LicenseReportType:
 			return license[key]
+		case OwnerReportType:
+			return owner[key]
+		case CrossTabReportType:
+			return crossTab[key]
 		default:
 			return bucket[key]
 		}
 	}
 }
 
+// sortBy sets the sort options of a report of reportType, once the column
+// is checked to be one of its columns.
+func sortBy(opts *ParamOptions, reportType, column string, asc bool) error {
+	if columnOptions()(reportType, column) == "" {
+		return fmt.Errorf("%q is not a column of this report", column)
+	}
+	opts.Column, opts.Asc = column, asc
+	return nil
+}
+
+// sortColumn returns the column a report is sorted by, the first of its
+// columns when none is set.
+func sortColumn(column string, columns []string) string {
+	if column == "" {
+		return columns[0]
+	}
+	return column
+}
+
 func NewReport(reportType string, opts ParamOptions) (StatsReport, error) {
-	if err := validateIDRange(opts.Since, opts.MaxID); err != nil {
+	if err := ValidateIDRange(opts.Since, opts.MaxID); err != nil {
 		return nil, err
 	}
+	if opts.Column != "" && columnOptions()(reportType, opts.Column) == "" {
+		return nil, fmt.Errorf("%q is not a column of this report", opts.Column)
+	}
 
 	switch reportType {
 	case StarGazersReportType:
 		return &BucketReport{
 			ParamOptions: opts,
 			report: report{
-				name:  starGazersReportName,
-				query: github.Query{Since: opts.Since, MaxID: opts.MaxID},
+				name:       starGazersReportName,
+				reportType: StarGazersReportType,
+				query:      github.Query{Since: opts.Since, MaxID: opts.MaxID},
 			},
 		}, nil
 	case LicenseReportType:
 		return &LicenseTypeReport{
 			ParamOptions: opts,
 			report: report{
-				name:  licenseTypesReportName,
-				query: github.Query{Since: opts.Since, MaxID: opts.MaxID},
+				name:       licenseTypesReportName,
+				reportType: LicenseReportType,
+				query:      github.Query{Since: opts.Since, MaxID: opts.MaxID},
+			},
+		}, nil
+	case OwnerReportType:
+		return &OwnerReport{
+			ParamOptions: opts,
+			report: report{
+				name:       ownersReportName,
+				reportType: OwnerReportType,
+				query:      github.Query{Since: opts.Since, MaxID: opts.MaxID},
+			},
+		}, nil
+	case CrossTabReportType:
+		return &CrossTabReport{
+			ParamOptions: opts,
+			report: report{
+				name:       crossTabReportName,
+				reportType: CrossTabReportType,
+				query:      github.Query{Since: opts.Since, MaxID: opts.MaxID},
 			},
 		}, nil
 	default:
@@ -97,26 +262,41 @@ func NewReport(reportType string, opts ParamOptions) (StatsReport, error) {
 	}
 }
 
+// queryRepos lists the repositories of the query, printing the progress
+// as pages are fetched.
 func queryRepos(ctx context.Context, gh *github.Github, query github.Query) ([]github.Repos, error) {
-	repos, err := gh.QueryRepos(ctx, query)
-	if err != nil {
+	var repos []github.Repos
+	pages := 0
+	it := gh.IterateRepos(ctx, query)
+	for it.Next() {
+		if it.Pages() != pages {
+			pages = it.Pages()
+			fmt.Fprintf(os.Stderr, "Listing page %d, from repository ID %d...\n", pages, it.Repo().ID)
+		}
+		repos = append(repos, it.Repo())
+	}
+	if err := it.Err(); err != nil {
 		return nil, err
 	}
 
+	fmt.Fprintf(os.Stderr, "Listed %d repositories in %d pages.\n", len(repos), it.Pages())
 	return repos, nil
 }
 
-func validateIDRange(since, max int) error {
-	const maxNumIDs = 500
+// MaxNumIDs is the length of the longest ID range a report crawls.
+const MaxNumIDs = 500
 
+// ValidateIDRange checks that the ID range is in order, and small enough
+// to be crawled.
+func ValidateIDRange(since, max int) error {
 	if max < since {
 		msg := fmt.Sprintf("the `maxID` value (%d) cannot be smaller than the `since` value (%d)", max, since)
 		return errors.New(msg)
 	}
 
 	numIDs := max - since
-	if numIDs > maxNumIDs {
-		msg := fmt.Sprintf("the number of IDs (%d)  has exceeded the limit (%d)", numIDs, maxNumIDs)
+	if numIDs > MaxNumIDs {
+		msg := fmt.Sprintf("the number of IDs (%d)  has exceeded the limit (%d)", numIDs, MaxNumIDs)
 		return errors.New(msg)
 	}
 
@@ -2,8 +2,12 @@ package analytics
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"sort"
+	"strconv"
 
 	"github.com/jedib0t/go-pretty/table"
 	"github.com/jedib0t/go-pretty/text"
@@ -23,34 +27,44 @@ type aggregateBucket struct {
 }
 
 func (b *BucketReport) Run(ctx context.Context, gh *github.Github) error {
-	repos, err := queryRepos(ctx, gh, b.report.query)
-	if err != nil {
+	b.report.printf("Getting star gazers information for each repository found...\n\n")
+
+	add, done := b.aggregator()
+	if err := b.report.crawl(ctx, gh, b.ParamOptions.Concurrency, add); err != nil {
 		return err
 	}
-	b.report.repoCount = len(repos)
+	done()
 
-	fmt.Print("Getting star gazers information for each repository found...\n\n")
+	return nil
+}
 
-	repoInfo, errs := gh.QueryStars(ctx, repos)
-	// TODO: include a prompt asking the user if errors should be displayed)
-	if len(errs) > 0 {
-		b.report.aggregatedErrors = append(b.report.aggregatedErrors, errs...)
+// aggregator returns the func adding a record to the buckets, and the one
+// sorting them once all the records are added.
+func (b *BucketReport) aggregator() (func(repoRecord), func()) {
+	buckets := make(map[string]*aggregateBucket)
+	add := func(r repoRecord) {
+		tier := github.BucketTier(r.stars())
+		bucket, ok := buckets[tier]
+		if !ok {
+			bucket = &aggregateBucket{bucket: tier}
+			buckets[tier] = bucket
+		}
+		bucket.repoCount++
+		bucket.starCount += r.stars()
 	}
 
-	i := 0
-	b.aggregate = make([]aggregateBucket, len(repoInfo))
-	for k, v := range repoInfo {
-		b.aggregate[i].bucket = k
-		for kk, vv := range v {
-			b.aggregate[i].repoCount = kk
-			b.aggregate[i].starCount = vv
+	done := func() {
+		b.aggregate = make([]aggregateBucket, 0, len(buckets))
+		for _, bucket := range buckets {
+			b.aggregate = append(b.aggregate, *bucket)
 		}
-		i++
+		b.sort()
 	}
+	return add, done
+}
 
-	b.sort()
-
-	return nil
+func (b *BucketReport) base() *report {
+	return &b.report
 }
 
 func (b *BucketReport) Count() int {
@@ -61,6 +75,45 @@ func (b *BucketReport) Name() string {
 	return b.report.name
 }
 
+func (b *BucketReport) Results() Results {
+	return b.report.results(b.ParamOptions)
+}
+
+// Stats returns the metrics of the stages of the run.
+func (b *BucketReport) Stats() PipelineStats {
+	return b.report.stats
+}
+
+// Distributions returns the repositories of the report per star bucket
+// and per license, to be charted.
+func (b *BucketReport) Distributions() Distributions {
+	return b.report.distributions()
+}
+
+// SetProgress sets the func the progress of the run is reported to.
+func (b *BucketReport) SetProgress(f func(Progress)) {
+	b.report.progress = f
+}
+
+// Columns returns the columns the report can be sorted by.
+func (b *BucketReport) Columns() []string {
+	return []string{bucketCol, repoCol, starCol}
+}
+
+// SortedBy returns the column, and the order, the report is sorted by.
+func (b *BucketReport) SortedBy() (string, bool) {
+	return sortColumn(b.ParamOptions.Column, b.Columns()), b.ParamOptions.Asc
+}
+
+// Sort sorts the buckets again, by one of Columns.
+func (b *BucketReport) Sort(column string, asc bool) error {
+	if err := sortBy(&b.ParamOptions, StarGazersReportType, column, asc); err != nil {
+		return err
+	}
+	b.sort()
+	return nil
+}
+
 func (b *BucketReport) sort() {
 	buckets := b.aggregate
 	b.ParamOptions.Column = columnOptions()(StarGazersReportType, b.ParamOptions.Column)
@@ -83,19 +136,26 @@ func (b *BucketReport) sort() {
 }
 
 func (b *BucketReport) PrintStats() {
-	tw := table.NewWriter()
-	tw.AppendHeader(table.Row{"bucket", "#repos", "bucket total stars", "avg stars/repo"})
-
 	fmt.Println("Printing a star bucket report...")
 	fmt.Println("Ordering by column: ", b.ParamOptions.Column)
 	fmt.Printf("Sorting by asc?: %v\n\n", b.ParamOptions.Asc)
 
+	fmt.Printf("Report of total number of repositories and stars per bucket:\n%s", b.Table().Render())
+	b.report.printUnretrieved()
+
+	if b.ParamOptions.Chart {
+		fmt.Printf("\n\n%s", b.report.charts())
+	}
+}
+
+// Table returns the table of the buckets, in sort order.
+func (b *BucketReport) Table() table.Writer {
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{"bucket", "#repos", "bucket total stars", "avg stars/repo"})
+
 	var allBucketsStarCount, allBucketsRepoCount int
 	for _, bucket := range b.aggregate {
-		var repoAverage float64
-		if bucket.repoCount > 0 {
-			repoAverage = float64(bucket.starCount) / float64(bucket.repoCount)
-		}
+		repoAverage := bucket.average()
 
 		allBucketsStarCount = allBucketsStarCount + bucket.starCount
 		allBucketsRepoCount += bucket.repoCount
@@ -109,5 +169,84 @@ func (b *BucketReport) PrintStats() {
 	tw.Style().Format.Header = text.FormatLower
 	tw.Style().Format.Row = text.FormatLower
 	tw.Style().Format.Footer = text.FormatLower
-	fmt.Printf("Report of total number of repositories and stars per bucket:\n%s", tw.Render())
+	return tw
+}
+
+type bucketJSON struct {
+	Report  string           `json:"report"`
+	Since   int              `json:"since"`
+	MaxID   int              `json:"max_id"`
+	Column  string           `json:"column"`
+	Asc     bool             `json:"asc"`
+	Buckets []bucketRowJSON  `json:"buckets"`
+	Totals  bucketTotalsJSON `json:"totals"`
+}
+
+type bucketRowJSON struct {
+	Bucket    string  `json:"bucket"`
+	RepoCount int     `json:"repos"`
+	StarCount int     `json:"stars"`
+	AvgStars  float64 `json:"avg_stars_per_repo"`
+}
+
+type bucketTotalsJSON struct {
+	RepoCount int `json:"repos"`
+	StarCount int `json:"stars"`
+}
+
+// WriteJSON writes the buckets, in sort order, as a JSON document.
+func (b *BucketReport) WriteJSON(w io.Writer) error {
+	doc := bucketJSON{
+		Report:  b.report.name,
+		Since:   b.ParamOptions.Since,
+		MaxID:   b.ParamOptions.MaxID,
+		Column:  b.ParamOptions.Column,
+		Asc:     b.ParamOptions.Asc,
+		Buckets: []bucketRowJSON{},
+	}
+
+	for _, bucket := range b.aggregate {
+		doc.Buckets = append(doc.Buckets, bucketRowJSON{
+			Bucket:    bucket.bucket,
+			RepoCount: bucket.repoCount,
+			StarCount: bucket.starCount,
+			AvgStars:  bucket.average(),
+		})
+		doc.Totals.RepoCount += bucket.repoCount
+		doc.Totals.StarCount += bucket.starCount
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// WriteCSV writes the buckets, in sort order, as CSV.
+func (b *BucketReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"bucket", "repos", "stars", "avg_stars_per_repo"}); err != nil {
+		return err
+	}
+
+	for _, bucket := range b.aggregate {
+		record := []string{
+			bucket.bucket,
+			strconv.Itoa(bucket.repoCount),
+			strconv.Itoa(bucket.starCount),
+			strconv.FormatFloat(bucket.average(), 'f', 2, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (a aggregateBucket) average() float64 {
+	if a.repoCount == 0 {
+		return 0
+	}
+	return float64(a.starCount) / float64(a.repoCount)
 }
@@ -0,0 +1,162 @@
+package analytics
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// chartWidth is the length of the longest bar of a chart, in characters.
+const chartWidth = 40
+
+// eighths are the blocks ending a bar, by eighth of a character, so that
+// bars of close values can still be told apart.
+var eighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// chartBar is a bar of a chart.
+type chartBar struct {
+	label string
+	value int
+}
+
+// barChart draws the bars horizontally, scaled to the largest value, each
+// one after its label and value.
+func barChart(title string, bars []chartBar) string {
+	labelWidth, valueWidth, max := 0, 0, 0
+	for _, b := range bars {
+		if len(b.label) > labelWidth {
+			labelWidth = len(b.label)
+		}
+		if n := len(strconv.Itoa(b.value)); n > valueWidth {
+			valueWidth = n
+		}
+		if b.value > max {
+			max = b.value
+		}
+	}
+
+	lines := []string{title + ":"}
+	for _, b := range bars {
+		var length int
+		if max > 0 {
+			length = b.value * chartWidth * 8 / max
+		}
+		lines = append(lines, fmt.Sprintf("  %-*s %*d │%s%s",
+			labelWidth, b.label, valueWidth, b.value, strings.Repeat("█", length/8), eighths[length%8]))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// starHistogram counts the star counts in bins doubling in width: 0, 1,
+// 2-3, 4-7, and so on up to the bin of the largest count, as they span
+// orders of magnitude.
+func starHistogram(stars []int) []chartBar {
+	max := 0
+	for _, s := range stars {
+		if s > max {
+			max = s
+		}
+	}
+
+	bins := []chartBar{{label: "0"}}
+	for low := 1; low <= max; low *= 2 {
+		label := strconv.Itoa(low)
+		if high := low*2 - 1; high > low {
+			label += "-" + strconv.Itoa(high)
+		}
+		bins = append(bins, chartBar{label: label})
+	}
+	for _, s := range stars {
+		// The bin of s is the number of bits of s, 0 having none.
+		bins[bits.Len(uint(s))].value++
+	}
+	return bins
+}
+
+// Charted is implemented by reports whose repositories can be charted by
+// star bucket and license.
+type Charted interface {
+	Distributions() Distributions
+}
+
+// Distributions are the repositories of a report per star bucket and per
+// license, aggregated as in the tables of the stargazers and license
+// reports.
+type Distributions struct {
+	// Buckets are in the order of github.BucketTiers, including the
+	// empty ones.
+	Buckets []Share
+	// Licenses are the most common first.
+	Licenses []Share
+}
+
+// Share is the number of repositories of a category.
+type Share struct {
+	Label string
+	Count int
+}
+
+// distributions aggregates the records of the report into the buckets
+// and licenses of the stargazers and license reports.
+func (r *report) distributions() Distributions {
+	var buckets BucketReport
+	var licenses LicenseTypeReport
+	addBucket, bucketsDone := buckets.aggregator()
+	addLicense, licensesDone := licenses.aggregator()
+	for _, record := range r.records {
+		addBucket(record)
+		addLicense(record)
+	}
+	bucketsDone()
+	licensesDone()
+
+	counts := make(map[string]int)
+	for _, bucket := range buckets.aggregate {
+		counts[bucket.bucket] = bucket.repoCount
+	}
+	var d Distributions
+	for _, tier := range github.BucketTiers {
+		d.Buckets = append(d.Buckets, Share{Label: tier, Count: counts[tier]})
+	}
+
+	for _, license := range licenses.aggregate {
+		d.Licenses = append(d.Licenses, Share{Label: license.license, Count: license.repoCount})
+	}
+	sort.Slice(d.Licenses, func(i, j int) bool {
+		if d.Licenses[i].Count != d.Licenses[j].Count {
+			return d.Licenses[i].Count > d.Licenses[j].Count
+		}
+		return d.Licenses[i].Label < d.Licenses[j].Label
+	})
+	return d
+}
+
+// charts draws the distributions of the records of the report: the
+// repositories per star bucket and per license, and the histogram of their
+// star counts on a log scale.
+func (r *report) charts() string {
+	d := r.distributions()
+	stars := make([]int, len(r.records))
+	for i, record := range r.records {
+		stars[i] = record.stars()
+	}
+
+	return strings.Join([]string{
+		barChart("Repositories per star bucket", shareBars(d.Buckets)),
+		barChart("Repositories per license", shareBars(d.Licenses)),
+		barChart("Repositories per star count (log scale)", starHistogram(stars)),
+	}, "\n\n")
+}
+
+// shareBars returns the bars of the shares.
+func shareBars(shares []Share) []chartBar {
+	bars := make([]chartBar, len(shares))
+	for i, share := range shares {
+		bars[i] = chartBar{label: share.Label, value: share.Count}
+	}
+	return bars
+}
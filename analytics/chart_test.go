@@ -0,0 +1,44 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+func Test_barChart(t *testing.T) {
+	chart := barChart("Repositories", []chartBar{{label: "MIT", value: 80}, {label: "none", value: 3}, {label: "GPL", value: 0}})
+	require.Equal(t, strings.Join([]string{
+		"Repositories:",
+		"  MIT  80 │" + strings.Repeat("█", 40),
+		"  none  3 │█▌",
+		"  GPL   0 │",
+	}, "\n"), chart)
+}
+
+func Test_starHistogram(t *testing.T) {
+	require.Equal(t, []chartBar{{label: "0"}}, starHistogram(nil))
+	require.Equal(t, []chartBar{
+		{label: "0", value: 2},
+		{label: "1", value: 1},
+		{label: "2-3", value: 2},
+		{label: "4-7", value: 0},
+		{label: "8-15", value: 1},
+	}, starHistogram([]int{0, 3, 1, 8, 0, 2}))
+}
+
+func Test_charts(t *testing.T) {
+	repo := func(stars int, license string) repoRecord {
+		return repoRecord{repo: github.Repos{StargazersCount: stars, License: github.License{Name: license}}}
+	}
+	r := report{records: []repoRecord{repo(5, "MIT"), repo(150, ""), repo(7, "Apache"), repo(0, "MIT")}}
+
+	charts := r.charts()
+	require.Contains(t, charts, "Repositories per star bucket:\n  0..10       3 │"+strings.Repeat("█", 40)+"\n  10..100     0 │\n  100..1000   1 │")
+	require.Contains(t, charts, "Repositories per license:\n  MIT    2 │"+strings.Repeat("█", 40)+"\n  Apache 1 │")
+	require.Contains(t, charts, "Repositories per star count (log scale):\n  0       1 │")
+	require.Contains(t, charts, "\n  128-255 1 │")
+}
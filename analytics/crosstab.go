@@ -0,0 +1,326 @@
+package analytics
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+const (
+	permissiveFamily   = "permissive"
+	weakCopyleftFamily = "weak copyleft"
+	copyleftFamily     = "copyleft"
+	publicDomainFamily = "public domain"
+	otherFamily        = "other"
+	noLicenseFamily    = "none"
+)
+
+// licenseFamilies are the matrix columns, from the least to the most
+// restrictive license family.
+var licenseFamilies = []string{
+	publicDomainFamily,
+	permissiveFamily,
+	weakCopyleftFamily,
+	copyleftFamily,
+	otherFamily,
+	noLicenseFamily,
+}
+
+// licenseFamily groups a license, by SPDX ID, into its family.
+func licenseFamily(spdxID string) string {
+	id := strings.ToUpper(spdxID)
+	switch {
+	case spdxID == "" || spdxID == noLicense:
+		return noLicenseFamily
+	case id == "UNLICENSE" || id == "CC0-1.0" || id == "WTFPL":
+		return publicDomainFamily
+	case id == "MIT" || id == "ISC" || id == "ZLIB" || id == "BSL-1.0" || id == "0BSD" ||
+		strings.HasPrefix(id, "APACHE-") || strings.HasPrefix(id, "BSD-") ||
+		strings.HasPrefix(id, "AFL-") || strings.HasPrefix(id, "MIT-"):
+		return permissiveFamily
+	case strings.HasPrefix(id, "LGPL-") || strings.HasPrefix(id, "MPL-") ||
+		strings.HasPrefix(id, "EPL-") || strings.HasPrefix(id, "OSL-") ||
+		strings.HasPrefix(id, "CDDL-") || strings.HasPrefix(id, "MS-RL"):
+		return weakCopyleftFamily
+	case strings.HasPrefix(id, "GPL-") || strings.HasPrefix(id, "AGPL-") ||
+		strings.HasPrefix(id, "EUPL-"):
+		return copyleftFamily
+	default:
+		return otherFamily
+	}
+}
+
+// CrossTabReport counts repositories per star bucket and license family,
+// to show how licensing changes with popularity.
+type CrossTabReport struct {
+	ParamOptions ParamOptions
+	report       report
+	aggregate    []aggregateCrossTab
+}
+
+type aggregateCrossTab struct {
+	bucket    string
+	repoCount int
+	// families is the number of repositories per license family.
+	families map[string]int
+}
+
+func (c *CrossTabReport) Run(ctx context.Context, gh *github.Github) error {
+	c.report.printf("Getting star gazers and license information for each repository found...\n\n")
+
+	add, done := c.aggregator()
+	if err := c.report.crawl(ctx, gh, c.ParamOptions.Concurrency, add); err != nil {
+		return err
+	}
+	done()
+
+	return nil
+}
+
+// aggregator returns the func counting a record in its bucket and license
+// family, and the one sorting the buckets once all the records are counted.
+func (c *CrossTabReport) aggregator() (func(repoRecord), func()) {
+	buckets := make(map[string]*aggregateCrossTab)
+	for _, tier := range github.BucketTiers {
+		buckets[tier] = &aggregateCrossTab{bucket: tier, families: make(map[string]int)}
+	}
+	add := func(r repoRecord) {
+		bucket := buckets[github.BucketTier(r.stars())]
+		bucket.repoCount++
+		bucket.families[licenseFamily(r.licenseID())]++
+	}
+
+	done := func() {
+		c.aggregate = make([]aggregateCrossTab, 0, len(buckets))
+		for _, tier := range github.BucketTiers {
+			c.aggregate = append(c.aggregate, *buckets[tier])
+		}
+		c.sort()
+	}
+	return add, done
+}
+
+func (c *CrossTabReport) base() *report {
+	return &c.report
+}
+
+func (c *CrossTabReport) Count() int {
+	return c.report.repoCount
+}
+
+func (c *CrossTabReport) Name() string {
+	return c.report.name
+}
+
+func (c *CrossTabReport) Results() Results {
+	return c.report.results(c.ParamOptions)
+}
+
+// Stats returns the metrics of the stages of the run.
+func (c *CrossTabReport) Stats() PipelineStats {
+	return c.report.stats
+}
+
+// Distributions returns the repositories of the report per star bucket
+// and per license, to be charted.
+func (c *CrossTabReport) Distributions() Distributions {
+	return c.report.distributions()
+}
+
+// SetProgress sets the func the progress of the run is reported to.
+func (c *CrossTabReport) SetProgress(f func(Progress)) {
+	c.report.progress = f
+}
+
+// Columns returns the columns the report can be sorted by.
+func (c *CrossTabReport) Columns() []string {
+	return []string{bucketCol, repoCol}
+}
+
+// SortedBy returns the column, and the order, the report is sorted by.
+func (c *CrossTabReport) SortedBy() (string, bool) {
+	return sortColumn(c.ParamOptions.Column, c.Columns()), c.ParamOptions.Asc
+}
+
+// Sort sorts the buckets again, by one of Columns.
+func (c *CrossTabReport) Sort(column string, asc bool) error {
+	if err := sortBy(&c.ParamOptions, CrossTabReportType, column, asc); err != nil {
+		return err
+	}
+	c.sort()
+	return nil
+}
+
+func (c *CrossTabReport) sort() {
+	buckets := c.aggregate
+	c.ParamOptions.Column = columnOptions()(CrossTabReportType, c.ParamOptions.Column)
+	sort.SliceStable(buckets, func(i, j int) bool {
+		var res bool
+		switch c.ParamOptions.Column {
+		case repoCol:
+			res = buckets[i].repoCount < buckets[j].repoCount
+		default:
+			res = github.BucketTierIndex(buckets[i].bucket) < github.BucketTierIndex(buckets[j].bucket)
+		}
+
+		if !c.ParamOptions.Asc {
+			return !res
+		}
+		return res
+	})
+}
+
+// totals returns the number of repositories per license family
+// across all buckets.
+func (c *CrossTabReport) totals() (map[string]int, int) {
+	families := make(map[string]int)
+	var all int
+	for _, bucket := range c.aggregate {
+		for family, count := range bucket.families {
+			families[family] += count
+		}
+		all += bucket.repoCount
+	}
+	return families, all
+}
+
+func (c *CrossTabReport) PrintStats() {
+	fmt.Println("Printing a license family by star bucket report...")
+	fmt.Println("Ordering by column: ", c.ParamOptions.Column)
+	fmt.Printf("Sorting by asc?: %v\n\n", c.ParamOptions.Asc)
+
+	fmt.Printf("Report of the number of repositories (and share of the bucket) per license family:\n%s", c.Table().Render())
+	c.report.printUnretrieved()
+
+	if c.ParamOptions.Chart {
+		fmt.Printf("\n\n%s", c.report.charts())
+	}
+}
+
+// Table returns the table of the buckets, in sort order, with a column
+// per license family.
+func (c *CrossTabReport) Table() table.Writer {
+	header := table.Row{"bucket"}
+	for _, family := range licenseFamilies {
+		header = append(header, family)
+	}
+	header = append(header, "#repos")
+
+	tw := table.NewWriter()
+	tw.AppendHeader(header)
+
+	for _, bucket := range c.aggregate {
+		row := table.Row{bucket.bucket}
+		for _, family := range licenseFamilies {
+			row = append(row, cell(bucket.families[family], bucket.repoCount))
+		}
+		tw.AppendRow(append(row, bucket.repoCount))
+	}
+
+	families, all := c.totals()
+	footer := table.Row{"total"}
+	for _, family := range licenseFamilies {
+		footer = append(footer, cell(families[family], all))
+	}
+	tw.AppendFooter(append(footer, all))
+
+	tw.SetStyle(table.StyleRounded)
+	tw.Style().Format.Header = text.FormatLower
+	tw.Style().Format.Row = text.FormatLower
+	tw.Style().Format.Footer = text.FormatLower
+	return tw
+}
+
+// cell formats a count along with its share of the row total.
+func cell(count, total int) string {
+	if total == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%d (%.0f%%)", count, float64(count)/float64(total)*100)
+}
+
+type crossTabJSON struct {
+	Report   string            `json:"report"`
+	Since    int               `json:"since"`
+	MaxID    int               `json:"max_id"`
+	Families []string          `json:"families"`
+	Buckets  []crossTabRowJSON `json:"buckets"`
+	Totals   crossTabRowJSON   `json:"totals"`
+}
+
+type crossTabRowJSON struct {
+	Bucket    string         `json:"bucket"`
+	RepoCount int            `json:"repos"`
+	Families  map[string]int `json:"families"`
+}
+
+// WriteJSON writes the matrix as a JSON document.
+func (c *CrossTabReport) WriteJSON(w io.Writer) error {
+	doc := crossTabJSON{
+		Report:   c.report.name,
+		Since:    c.ParamOptions.Since,
+		MaxID:    c.ParamOptions.MaxID,
+		Families: licenseFamilies,
+	}
+
+	for _, bucket := range c.aggregate {
+		row := crossTabRowJSON{Bucket: bucket.bucket, RepoCount: bucket.repoCount, Families: make(map[string]int)}
+		for _, family := range licenseFamilies {
+			row.Families[family] = bucket.families[family]
+		}
+		doc.Buckets = append(doc.Buckets, row)
+	}
+
+	families, all := c.totals()
+	doc.Totals = crossTabRowJSON{Bucket: "total", RepoCount: all, Families: make(map[string]int)}
+	for _, family := range licenseFamilies {
+		doc.Totals.Families[family] = families[family]
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// WriteCSV writes the matrix as CSV, with one row per bucket and
+// one column per license family.
+func (c *CrossTabReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := append([]string{"bucket"}, licenseFamilies...)
+	if err := cw.Write(append(header, "repos")); err != nil {
+		return err
+	}
+
+	for _, bucket := range c.aggregate {
+		record := []string{bucket.bucket}
+		for _, family := range licenseFamilies {
+			record = append(record, strconv.Itoa(bucket.families[family]))
+		}
+		if err := cw.Write(append(record, strconv.Itoa(bucket.repoCount))); err != nil {
+			return err
+		}
+	}
+
+	families, all := c.totals()
+	record := []string{"total"}
+	for _, family := range licenseFamilies {
+		record = append(record, strconv.Itoa(families[family]))
+	}
+	if err := cw.Write(append(record, strconv.Itoa(all))); err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_licenseFamily(t *testing.T) {
+	tc := map[string]string{
+		"":             noLicenseFamily,
+		noLicense:      noLicenseFamily,
+		"MIT":          permissiveFamily,
+		"Apache-2.0":   permissiveFamily,
+		"BSD-3-Clause": permissiveFamily,
+		"ISC":          permissiveFamily,
+		"Unlicense":    publicDomainFamily,
+		"CC0-1.0":      publicDomainFamily,
+		"LGPL-2.1":     weakCopyleftFamily,
+		"MPL-2.0":      weakCopyleftFamily,
+		"EPL-2.0":      weakCopyleftFamily,
+		"GPL-3.0":      copyleftFamily,
+		"AGPL-3.0":     copyleftFamily,
+		"NOASSERTION":  otherFamily,
+	}
+
+	for spdxID, family := range tc {
+		require.Equal(t, family, licenseFamily(spdxID), spdxID)
+	}
+}
+
+func TestCrossTabReport_WriteCSV(t *testing.T) {
+	c := CrossTabReport{
+		aggregate: []aggregateCrossTab{
+			{bucket: "0..10", repoCount: 3, families: map[string]int{permissiveFamily: 2, noLicenseFamily: 1}},
+			{bucket: "10..100", repoCount: 1, families: map[string]int{copyleftFamily: 1}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, c.WriteCSV(&buf))
+	require.Equal(t, "bucket,public domain,permissive,weak copyleft,copyleft,other,none,repos\n"+
+		"0..10,0,2,0,0,0,1,3\n"+
+		"10..100,0,0,0,1,0,0,1\n"+
+		"total,0,2,0,1,0,1,4\n", buf.String())
+}
@@ -0,0 +1,113 @@
+package analytics
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// Header is the section written above the table of a report in a document:
+// what was queried, when, and what it cost.
+type Header struct {
+	Since, MaxID int
+	// At is when the data of the report was retrieved.
+	At time.Time
+	// Source describes where the data was taken from, when it wasn't
+	// just retrieved, e.g. a snapshot.
+	Source string
+	// API are the calls made to the GitHub API for the report, nil when
+	// none were made.
+	API *APIStats
+}
+
+// APIStats are the calls made to the GitHub API.
+type APIStats struct {
+	Requests int
+	Failed   int
+	Retries  int
+	// RateLimit is the number of requests left in the rate limit, -1
+	// when unknown.
+	RateLimit int
+}
+
+// WriteMarkdown writes a report that is Tabular as a Markdown document,
+// its header as a list above its table, e.g. to be pasted in a GitHub
+// issue.
+func WriteMarkdown(w io.Writer, report StatsReport, h Header) error {
+	tabular, ok := report.(Tabular)
+	if !ok {
+		return fmt.Errorf("the %s can't be written as a table", report.Name())
+	}
+
+	var doc strings.Builder
+	fmt.Fprintf(&doc, "## %s\n\n", report.Name())
+	for _, field := range h.fields(report) {
+		fmt.Fprintf(&doc, "- **%s:** %s\n", field[0], field[1])
+	}
+	fmt.Fprintf(&doc, "\n%s\n", tabular.Table().RenderMarkdown())
+
+	_, err := io.WriteString(w, doc.String())
+	return err
+}
+
+// WriteHTML writes a report that is Tabular as an HTML fragment, its header
+// as a list above its table, e.g. to be pasted in a wiki page.
+func WriteHTML(w io.Writer, report StatsReport, h Header) error {
+	tabular, ok := report.(Tabular)
+	if !ok {
+		return fmt.Errorf("the %s can't be written as a table", report.Name())
+	}
+
+	var doc strings.Builder
+	fmt.Fprintf(&doc, "<h2>%s</h2>\n<ul>\n", html.EscapeString(report.Name()))
+	for _, field := range h.fields(report) {
+		fmt.Fprintf(&doc, "  <li><strong>%s:</strong> %s</li>\n", html.EscapeString(field[0]), html.EscapeString(field[1]))
+	}
+	fmt.Fprintf(&doc, "</ul>\n%s\n", tabular.Table().RenderHTML())
+
+	_, err := io.WriteString(w, doc.String())
+	return err
+}
+
+// fields returns the names and values of the header of the report.
+func (h Header) fields(report StatsReport) [][2]string {
+	retrieved := h.At.UTC().Format(time.RFC3339)
+	if h.Source != "" {
+		retrieved += ", from " + h.Source
+	}
+	fields := [][2]string{
+		{"Repository IDs", fmt.Sprintf("%d to %d", h.Since+1, h.MaxID)},
+		{"Retrieved at", retrieved},
+	}
+
+	repos := fmt.Sprintf("%d", report.Count())
+	if staged, ok := report.(Staged); ok && staged.Stats().Listed > 0 {
+		s := staged.Stats()
+		repos = fmt.Sprintf("%d listed, %d retrieved, %d failed", s.Listed, s.Enriched, s.EnrichErrors)
+		// Rebuilt reports aren't timed.
+		if s.Elapsed > 0 {
+			repos += fmt.Sprintf(", in %s with %d workers", s.Elapsed.Round(time.Millisecond), s.Workers)
+		}
+	}
+	fields = append(fields, [2]string{"Repositories", repos})
+
+	if sortable, ok := report.(Sortable); ok {
+		column, asc := sortable.SortedBy()
+		order := "asc"
+		if !asc {
+			order = "desc"
+		}
+		fields = append(fields, [2]string{"Sorted by", column + " (" + order + ")"})
+	}
+
+	if h.API != nil {
+		api := fmt.Sprintf("%d requests, %d failed, %d retried", h.API.Requests, h.API.Failed, h.API.Retries)
+		if h.API.RateLimit >= 0 {
+			api += fmt.Sprintf(", %d left in the rate limit", h.API.RateLimit)
+		}
+		fields = append(fields, [2]string{"GitHub API", api})
+	}
+	return fields
+}
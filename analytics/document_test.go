@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+func TestWriteDocuments(t *testing.T) {
+	report, err := Rebuild(Results{
+		ReportType: LicenseReportType,
+		Options:    ParamOptions{Since: 10, MaxID: 20, Column: repoCol, Asc: true},
+		Listed:     make([]github.Repos, 3),
+		Enriched: []github.Repos{
+			{ID: 11, License: github.License{Name: `BSD 3-Clause "New" or "Revised" License`}},
+			{ID: 12, License: github.License{Name: "MIT License"}},
+			{ID: 15, License: github.License{Name: "MIT License"}},
+		},
+	})
+	require.NoError(t, err)
+	header := Header{
+		Since:  10,
+		MaxID:  20,
+		At:     time.Date(2021, 7, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600)),
+		Source: "snapshot s1",
+		API:    &APIStats{Requests: 4, Failed: 1, Retries: 1, RateLimit: -1},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, report, header))
+	require.Equal(t, `## License Types Report
+
+- **Repository IDs:** 11 to 20
+- **Retrieved at:** 2021-07-01T10:00:00Z, from snapshot s1
+- **Repositories:** 3 listed, 3 retrieved, 0 failed
+- **Sorted by:** repos (asc)
+- **GitHub API:** 4 requests, 1 failed, 1 retried
+
+| license type | #repos |
+| --- | ---:|
+| BSD 3-Clause "New" or "Revised" License | 1 |
+| MIT License | 2 |
+| total | 3 |
+`, buf.String())
+
+	buf.Reset()
+	header.API.RateLimit = 4000
+	require.NoError(t, WriteHTML(&buf, report, header))
+	require.Contains(t, buf.String(), "<h2>License Types Report</h2>\n<ul>\n  <li><strong>Repository IDs:</strong> 11 to 20</li>\n")
+	require.Contains(t, buf.String(), "<li><strong>GitHub API:</strong> 4 requests, 1 failed, 1 retried, 4000 left in the rate limit</li>\n</ul>\n<table")
+	require.Contains(t, buf.String(), "<td>BSD 3-Clause &#34;New&#34; or &#34;Revised&#34; License</td>")
+}
@@ -0,0 +1,44 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// noLicense labels repositories that don't have a license.
+const noLicense = "none"
+
+// repoRecord is a listed repository along with the star count and
+// license it was enriched with.
+type repoRecord struct {
+	repo github.Repos
+}
+
+func (r repoRecord) stars() int {
+	return r.repo.StargazersCount
+}
+
+// licenseID returns the SPDX ID of the record's license, or
+// noLicense when it doesn't have one.
+func (r repoRecord) licenseID() string {
+	if r.repo.License.SpdxID == "" {
+		return noLicense
+	}
+	return r.repo.License.SpdxID
+}
+
+// enrichRepo fetches the full record of a listed repository, which holds
+// both its star count and its license, so that reports needing either or
+// both cost a single request per repository.
+func enrichRepo(ctx context.Context, gh *github.Github, r github.Repos) (repoRecord, error) {
+	full, err := gh.Repo(ctx, r.Owner.Login, r.Name)
+	if err != nil {
+		return repoRecord{}, err
+	}
+
+	repo := r
+	repo.StargazersCount = full.StargazersCount
+	repo.License = full.License
+	return repoRecord{repo: repo}, nil
+}
@@ -2,8 +2,12 @@ package analytics
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"sort"
+	"strconv"
 
 	"github.com/jedib0t/go-pretty/table"
 	"github.com/jedib0t/go-pretty/text"
@@ -15,6 +19,9 @@ type LicenseTypeReport struct {
 	ParamOptions ParamOptions
 	report       report
 	aggregate    []aggregateLicense
+	// unlicensed is the number of repositories without a license, which
+	// aren't counted in the aggregate.
+	unlicensed int
 }
 
 type aggregateLicense struct {
@@ -23,27 +30,42 @@ type aggregateLicense struct {
 }
 
 func (l *LicenseTypeReport) Run(ctx context.Context, gh *github.Github) error {
-	repos, err := queryRepos(ctx, gh, l.report.query)
-	if err != nil {
+	l.report.printf("Getting license type information for each repository found...\n\n")
+
+	add, done := l.aggregator()
+	if err := l.report.crawl(ctx, gh, l.ParamOptions.Concurrency, add); err != nil {
 		return err
 	}
-	l.report.repoCount = len(repos)
-
-	fmt.Print("Getting license type information for each repository found...\n\n")
+	done()
 
-	repoInfo := gh.QueryLicenses(ctx, repos)
+	return nil
+}
 
-	i := 0
-	l.aggregate = make([]aggregateLicense, len(repoInfo))
-	for k, v := range repoInfo {
-		l.aggregate[i].license = k
-		l.aggregate[i].repoCount = v
-		i++
+// aggregator returns the func counting a record in its license, and the
+// one sorting the licenses once all the records are counted. Records
+// without a license are only counted apart.
+func (l *LicenseTypeReport) aggregator() (func(repoRecord), func()) {
+	licenses := make(map[string]int)
+	add := func(r repoRecord) {
+		if r.repo.License.Name == "" {
+			l.unlicensed++
+			return
+		}
+		licenses[r.repo.License.Name]++
 	}
 
-	l.sort()
+	done := func() {
+		l.aggregate = make([]aggregateLicense, 0, len(licenses))
+		for license, repoCount := range licenses {
+			l.aggregate = append(l.aggregate, aggregateLicense{license: license, repoCount: repoCount})
+		}
+		l.sort()
+	}
+	return add, done
+}
 
-	return nil
+func (l *LicenseTypeReport) base() *report {
+	return &l.report
 }
 
 func (l *LicenseTypeReport) Count() int {
@@ -54,6 +76,45 @@ func (l *LicenseTypeReport) Name() string {
 	return l.report.name
 }
 
+func (l *LicenseTypeReport) Results() Results {
+	return l.report.results(l.ParamOptions)
+}
+
+// Stats returns the metrics of the stages of the run.
+func (l *LicenseTypeReport) Stats() PipelineStats {
+	return l.report.stats
+}
+
+// Distributions returns the repositories of the report per star bucket
+// and per license, to be charted.
+func (l *LicenseTypeReport) Distributions() Distributions {
+	return l.report.distributions()
+}
+
+// SetProgress sets the func the progress of the run is reported to.
+func (l *LicenseTypeReport) SetProgress(f func(Progress)) {
+	l.report.progress = f
+}
+
+// Columns returns the columns the report can be sorted by.
+func (l *LicenseTypeReport) Columns() []string {
+	return []string{licenseCol, repoCol}
+}
+
+// SortedBy returns the column, and the order, the report is sorted by.
+func (l *LicenseTypeReport) SortedBy() (string, bool) {
+	return sortColumn(l.ParamOptions.Column, l.Columns()), l.ParamOptions.Asc
+}
+
+// Sort sorts the licenses again, by one of Columns.
+func (l *LicenseTypeReport) Sort(column string, asc bool) error {
+	if err := sortBy(&l.ParamOptions, LicenseReportType, column, asc); err != nil {
+		return err
+	}
+	l.sort()
+	return nil
+}
+
 func (l *LicenseTypeReport) sort() {
 	licenses := l.aggregate
 	l.ParamOptions.Column = columnOptions()(LicenseReportType, l.ParamOptions.Column)
@@ -74,18 +135,31 @@ func (l *LicenseTypeReport) sort() {
 }
 
 func (l *LicenseTypeReport) PrintStats() {
-	tw := table.NewWriter()
-	tw.AppendHeader(table.Row{"license type", "#repos"})
-
 	fmt.Println("Printing a license report...")
 	fmt.Println("Ordering by column: ", l.ParamOptions.Column)
 	fmt.Printf("Sorting by asc?: %v\n\n", l.ParamOptions.Asc)
 
+	fmt.Printf("Report of total number of repositories per license:\n%s", l.Table().Render())
+	if l.unlicensed > 0 {
+		fmt.Printf("\n%d repositories without a license aren't counted.", l.unlicensed)
+	}
+	l.report.printUnretrieved()
+
+	if l.ParamOptions.Chart {
+		fmt.Printf("\n\n%s", l.report.charts())
+	}
+}
+
+// Table returns the table of the licenses, in sort order.
+func (l *LicenseTypeReport) Table() table.Writer {
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{"license type", "#repos"})
+
 	var allLicensessRepoCount int
 	for _, license := range l.aggregate {
 		allLicensessRepoCount += license.repoCount
 		tw.AppendRows([]table.Row{
-			{license, license.repoCount},
+			{license.license, license.repoCount},
 		})
 	}
 
@@ -94,5 +168,58 @@ func (l *LicenseTypeReport) PrintStats() {
 	tw.Style().Format.Header = text.FormatLower
 	tw.Style().Format.Row = text.FormatLower
 	tw.Style().Format.Footer = text.FormatLower
-	fmt.Printf("Report of total number of repositories per license:\n%s", tw.Render())
+	return tw
+}
+
+type licenseJSON struct {
+	Report    string           `json:"report"`
+	Since     int              `json:"since"`
+	MaxID     int              `json:"max_id"`
+	Column    string           `json:"column"`
+	Asc       bool             `json:"asc"`
+	Licenses  []licenseRowJSON `json:"licenses"`
+	RepoCount int              `json:"repos"`
+}
+
+type licenseRowJSON struct {
+	License   string `json:"license"`
+	RepoCount int    `json:"repos"`
+}
+
+// WriteJSON writes the licenses, in sort order, as a JSON document.
+func (l *LicenseTypeReport) WriteJSON(w io.Writer) error {
+	doc := licenseJSON{
+		Report:   l.report.name,
+		Since:    l.ParamOptions.Since,
+		MaxID:    l.ParamOptions.MaxID,
+		Column:   l.ParamOptions.Column,
+		Asc:      l.ParamOptions.Asc,
+		Licenses: []licenseRowJSON{},
+	}
+
+	for _, license := range l.aggregate {
+		doc.Licenses = append(doc.Licenses, licenseRowJSON{License: license.license, RepoCount: license.repoCount})
+		doc.RepoCount += license.repoCount
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// WriteCSV writes the licenses, in sort order, as CSV.
+func (l *LicenseTypeReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"license", "repos"}); err != nil {
+		return err
+	}
+
+	for _, license := range l.aggregate {
+		if err := cw.Write([]string{license.license, strconv.Itoa(license.repoCount)}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
 }
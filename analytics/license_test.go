@@ -0,0 +1,53 @@
+package analytics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestLicenseTypeReport asserts that only the retrieved repositories with
+// a license are counted in the licenses, the other ones apart.
+func TestLicenseTypeReport(t *testing.T) {
+	server := newFake(t, 40)
+	repos := server.Repos()
+	server.FailNext("/repos/"+repos[3].FullName, http.StatusNotFound)
+
+	report, err := NewReport(LicenseReportType, ParamOptions{Since: 999, MaxID: repos[len(repos)-1].ID})
+	require.NoError(t, err)
+	report.(Watchable).SetProgress(func(Progress) {})
+	require.NoError(t, report.Run(context.Background(), server.Github()))
+	l := report.(*LicenseTypeReport)
+
+	expected := make(map[string]int)
+	unlicensed := 0
+	for i, repo := range repos {
+		switch {
+		case i == 3:
+		case repo.License.Name == "":
+			unlicensed++
+		default:
+			expected[repo.License.Name]++
+		}
+	}
+	require.NotZero(t, unlicensed)
+
+	counted := make(map[string]int)
+	for _, license := range l.aggregate {
+		counted[license.license] = license.repoCount
+	}
+	require.Equal(t, expected, counted)
+	require.Equal(t, unlicensed, l.unlicensed)
+	require.Equal(t, len(repos), l.Count())
+	require.Len(t, l.report.records, len(repos)-1)
+
+	// The license column holds the license names, not the aggregates.
+	rendered := l.Table().Render()
+	for name := range expected {
+		require.Contains(t, rendered, "│ "+strings.ToLower(name)+" ")
+	}
+	require.NotContains(t, rendered, "{")
+}
@@ -0,0 +1,289 @@
+package analytics
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+type OwnerReport struct {
+	ParamOptions ParamOptions
+	report       report
+	aggregate    []aggregateOwner
+}
+
+type aggregateOwner struct {
+	login, ownerType     string
+	repoCount, starCount int
+	// licenses is the number of repositories per license SPDX ID.
+	licenses map[string]int
+}
+
+func (o *OwnerReport) Run(ctx context.Context, gh *github.Github) error {
+	o.report.printf("Getting star gazers and license information for each repository found...\n\n")
+
+	add, done := o.aggregator()
+	if err := o.report.crawl(ctx, gh, o.ParamOptions.Concurrency, add); err != nil {
+		return err
+	}
+	done()
+
+	return nil
+}
+
+// aggregator returns the func adding a record to its owner, and the one
+// sorting the owners once all the records are added.
+func (o *OwnerReport) aggregator() (func(repoRecord), func()) {
+	owners := make(map[string]*aggregateOwner)
+	add := func(r repoRecord) {
+		owner, ok := owners[r.repo.Owner.Login]
+		if !ok {
+			owner = &aggregateOwner{
+				login:     r.repo.Owner.Login,
+				ownerType: r.repo.Owner.Type,
+				licenses:  make(map[string]int),
+			}
+			owners[owner.login] = owner
+		}
+		owner.repoCount++
+		owner.starCount += r.stars()
+		owner.licenses[r.licenseID()]++
+	}
+
+	done := func() {
+		o.aggregate = make([]aggregateOwner, 0, len(owners))
+		for _, owner := range owners {
+			o.aggregate = append(o.aggregate, *owner)
+		}
+		o.sort()
+	}
+	return add, done
+}
+
+func (o *OwnerReport) base() *report {
+	return &o.report
+}
+
+func (o *OwnerReport) Count() int {
+	return o.report.repoCount
+}
+
+func (o *OwnerReport) Name() string {
+	return o.report.name
+}
+
+func (o *OwnerReport) Results() Results {
+	return o.report.results(o.ParamOptions)
+}
+
+// Stats returns the metrics of the stages of the run.
+func (o *OwnerReport) Stats() PipelineStats {
+	return o.report.stats
+}
+
+// Distributions returns the repositories of the report per star bucket
+// and per license, to be charted.
+func (o *OwnerReport) Distributions() Distributions {
+	return o.report.distributions()
+}
+
+// SetProgress sets the func the progress of the run is reported to.
+func (o *OwnerReport) SetProgress(f func(Progress)) {
+	o.report.progress = f
+}
+
+// Columns returns the columns the report can be sorted by.
+func (o *OwnerReport) Columns() []string {
+	return []string{ownerCol, ownerTypeCol, repoCol, starCol}
+}
+
+// SortedBy returns the column, and the order, the report is sorted by.
+func (o *OwnerReport) SortedBy() (string, bool) {
+	return sortColumn(o.ParamOptions.Column, o.Columns()), o.ParamOptions.Asc
+}
+
+// Sort sorts the owners again, by one of Columns.
+func (o *OwnerReport) Sort(column string, asc bool) error {
+	if err := sortBy(&o.ParamOptions, OwnerReportType, column, asc); err != nil {
+		return err
+	}
+	o.sort()
+	return nil
+}
+
+func (o *OwnerReport) sort() {
+	owners := o.aggregate
+	o.ParamOptions.Column = columnOptions()(OwnerReportType, o.ParamOptions.Column)
+	sort.Slice(owners, func(i, j int) bool {
+		var res bool
+		switch o.ParamOptions.Column {
+		case ownerTypeCol:
+			res = owners[i].ownerType < owners[j].ownerType
+		case repoCol:
+			res = owners[i].repoCount < owners[j].repoCount
+		case starCol:
+			res = owners[i].starCount < owners[j].starCount
+		default:
+			res = owners[i].login < owners[j].login
+		}
+
+		if !o.ParamOptions.Asc {
+			return !res
+		}
+		return res
+	})
+}
+
+// top returns the first `Top` owners in sort order, or all
+// of them when `Top` isn't set.
+func (o *OwnerReport) top() []aggregateOwner {
+	if o.ParamOptions.Top > 0 && o.ParamOptions.Top < len(o.aggregate) {
+		return o.aggregate[:o.ParamOptions.Top]
+	}
+	return o.aggregate
+}
+
+func (o *OwnerReport) PrintStats() {
+	fmt.Println("Printing an owner report...")
+	fmt.Println("Ordering by column: ", o.ParamOptions.Column)
+	fmt.Printf("Sorting by asc?: %v\n", o.ParamOptions.Asc)
+	if o.ParamOptions.Top > 0 {
+		fmt.Printf("Showing the top %d of %d owners\n", len(o.top()), len(o.aggregate))
+	}
+	fmt.Println()
+
+	fmt.Printf("Report of total number of repositories and stars per owner:\n%s", o.Table().Render())
+	o.report.printUnretrieved()
+
+	if o.ParamOptions.Chart {
+		fmt.Printf("\n\n%s", o.report.charts())
+	}
+}
+
+// Table returns the table of the top owners, in sort order. The footer
+// totals every owner, shown or not.
+func (o *OwnerReport) Table() table.Writer {
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{"owner", "type", "#repos", "total stars", "licenses"})
+
+	for _, owner := range o.top() {
+		tw.AppendRows([]table.Row{
+			{owner.login, owner.ownerType, owner.repoCount, owner.starCount, licenseMix(owner.licenses)},
+		})
+	}
+
+	var allOwnersRepoCount, allOwnersStarCount int
+	for _, owner := range o.aggregate {
+		allOwnersRepoCount += owner.repoCount
+		allOwnersStarCount += owner.starCount
+	}
+	total := "total"
+	if len(o.top()) < len(o.aggregate) {
+		total = fmt.Sprintf("total of all %d owners", len(o.aggregate))
+	}
+	tw.AppendFooter(table.Row{total, "", allOwnersRepoCount, allOwnersStarCount, ""})
+	tw.SetStyle(table.StyleRounded)
+	tw.Style().Format.Header = text.FormatLower
+	tw.Style().Format.Footer = text.FormatLower
+	return tw
+}
+
+// licenseMix formats the number of repositories per license, most
+// used license first, e.g. "MIT: 3, Apache-2.0: 1".
+func licenseMix(licenses map[string]int) string {
+	ids := make([]string, 0, len(licenses))
+	for id := range licenses {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if licenses[ids[i]] != licenses[ids[j]] {
+			return licenses[ids[i]] > licenses[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+
+	mix := make([]string, len(ids))
+	for i, id := range ids {
+		mix[i] = fmt.Sprintf("%s: %d", id, licenses[id])
+	}
+	return strings.Join(mix, ", ")
+}
+
+type ownerJSON struct {
+	Report string         `json:"report"`
+	Since  int            `json:"since"`
+	MaxID  int            `json:"max_id"`
+	Column string         `json:"column"`
+	Asc    bool           `json:"asc"`
+	Top    int            `json:"top,omitempty"`
+	Owners []ownerRowJSON `json:"owners"`
+}
+
+type ownerRowJSON struct {
+	Login     string         `json:"owner"`
+	OwnerType string         `json:"type"`
+	RepoCount int            `json:"repos"`
+	StarCount int            `json:"stars"`
+	Licenses  map[string]int `json:"licenses"`
+}
+
+// WriteJSON writes the top owners, in sort order, as a JSON document.
+func (o *OwnerReport) WriteJSON(w io.Writer) error {
+	doc := ownerJSON{
+		Report: o.report.name,
+		Since:  o.ParamOptions.Since,
+		MaxID:  o.ParamOptions.MaxID,
+		Column: o.ParamOptions.Column,
+		Asc:    o.ParamOptions.Asc,
+		Top:    o.ParamOptions.Top,
+		Owners: []ownerRowJSON{},
+	}
+
+	for _, owner := range o.top() {
+		doc.Owners = append(doc.Owners, ownerRowJSON{
+			Login:     owner.login,
+			OwnerType: owner.ownerType,
+			RepoCount: owner.repoCount,
+			StarCount: owner.starCount,
+			Licenses:  owner.licenses,
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// WriteCSV writes the top owners, in sort order, as CSV.
+func (o *OwnerReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"owner", "type", "repos", "stars", "licenses"}); err != nil {
+		return err
+	}
+
+	for _, owner := range o.top() {
+		record := []string{
+			owner.login,
+			owner.ownerType,
+			strconv.Itoa(owner.repoCount),
+			strconv.Itoa(owner.starCount),
+			licenseMix(owner.licenses),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
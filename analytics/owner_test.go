@@ -0,0 +1,103 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+func ownerRecord(login, ownerType string, stars int, spdxID string) repoRecord {
+	return repoRecord{repo: github.Repos{
+		Owner:           github.Owner{Login: login, Type: ownerType},
+		StargazersCount: stars,
+		License:         github.License{SpdxID: spdxID},
+	}}
+}
+
+// newOwnerReport returns an owner report of a few repositories, whose
+// owners have no ties in any column.
+func newOwnerReport(opts ParamOptions) *OwnerReport {
+	o := &OwnerReport{ParamOptions: opts}
+	add, done := o.aggregator()
+	for _, r := range []repoRecord{
+		ownerRecord("carol", "User", 1, ""),
+		ownerRecord("acme", "Organization", 40, "MIT"),
+		ownerRecord("bob", "Bot", 7, "GPL-3.0"),
+		ownerRecord("acme", "Organization", 2, "Apache-2.0"),
+		ownerRecord("acme", "Organization", 10, "MIT"),
+		ownerRecord("bob", "Bot", 0, "MIT"),
+	} {
+		add(r)
+	}
+	done()
+	return o
+}
+
+func TestOwnerReport_aggregator(t *testing.T) {
+	o := newOwnerReport(ParamOptions{Column: ownerCol, Asc: true})
+
+	require.Equal(t, []aggregateOwner{
+		{login: "acme", ownerType: "Organization", repoCount: 3, starCount: 52, licenses: map[string]int{"MIT": 2, "Apache-2.0": 1}},
+		{login: "bob", ownerType: "Bot", repoCount: 2, starCount: 7, licenses: map[string]int{"GPL-3.0": 1, "MIT": 1}},
+		{login: "carol", ownerType: "User", repoCount: 1, starCount: 1, licenses: map[string]int{noLicense: 1}},
+	}, o.aggregate)
+}
+
+func TestOwnerReport_Sort(t *testing.T) {
+	testCases := []struct {
+		column   string
+		asc      bool
+		top      int
+		expected []string
+	}{
+		{column: ownerCol, asc: true, expected: []string{"acme", "bob", "carol"}},
+		{column: ownerCol, expected: []string{"carol", "bob", "acme"}},
+		{column: ownerTypeCol, asc: true, expected: []string{"bob", "acme", "carol"}},
+		{column: repoCol, asc: true, expected: []string{"carol", "bob", "acme"}},
+		{column: starCol, expected: []string{"acme", "bob", "carol"}},
+		{column: "4", expected: []string{"acme", "bob", "carol"}},
+		{column: starCol, top: 2, expected: []string{"acme", "bob"}},
+		{column: starCol, asc: true, top: 1, expected: []string{"carol"}},
+		{column: starCol, top: 5, expected: []string{"acme", "bob", "carol"}},
+	}
+
+	for _, tc := range testCases {
+		o := newOwnerReport(ParamOptions{Column: repoCol, Top: tc.top})
+		require.NoError(t, o.Sort(tc.column, tc.asc))
+
+		var logins []string
+		for _, owner := range o.top() {
+			logins = append(logins, owner.login)
+		}
+		require.Equal(t, tc.expected, logins, "%s asc=%v top=%d", tc.column, tc.asc, tc.top)
+	}
+}
+
+func TestOwnerReport_Table(t *testing.T) {
+	o := newOwnerReport(ParamOptions{Column: starCol})
+	require.Contains(t, o.Table().Render(), "│ total │              │      6 │          60 │")
+
+	// The footer totals every owner, not only the ones shown.
+	o = newOwnerReport(ParamOptions{Column: starCol, Top: 1})
+	rendered := o.Table().Render()
+	require.NotContains(t, rendered, "bob")
+	require.Contains(t, rendered, "│ total of all 3 owners │              │      6 │          60 │")
+}
+
+func Test_licenseMix(t *testing.T) {
+	tc := []struct {
+		licenses map[string]int
+		expected string
+	}{
+		{licenses: map[string]int{}, expected: ""},
+		{licenses: map[string]int{"MIT": 1}, expected: "MIT: 1"},
+		{licenses: map[string]int{"MIT": 1, "Apache-2.0": 3}, expected: "Apache-2.0: 3, MIT: 1"},
+		{licenses: map[string]int{noLicense: 2, "MIT": 2, "GPL-3.0": 1}, expected: "MIT: 2, " + noLicense + ": 2, GPL-3.0: 1"},
+	}
+
+	for _, tc := range tc {
+		require.Equal(t, tc.expected, licenseMix(tc.licenses))
+	}
+}
@@ -0,0 +1,225 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// DefaultConcurrency is the number of repositories enriched at the same
+// time when ParamOptions.Concurrency is not set.
+const DefaultConcurrency = 4
+
+// PipelineStats are the metrics of each stage of a report run: the listing
+// of the ID range, the enrichment workers and the aggregation.
+type PipelineStats struct {
+	Listed int
+	Pages  int
+	// ListBlocked is how long the listing waited for a free enrichment
+	// worker, when it lists faster than they enrich.
+	ListBlocked time.Duration
+	ListTime    time.Duration
+
+	Workers      int
+	Enriched     int
+	EnrichErrors int
+	// EnrichBusy is the time spent enriching, summed over the workers.
+	EnrichBusy time.Duration
+
+	Aggregated int
+	Elapsed    time.Duration
+}
+
+// Progress is the state of a report run, as it goes.
+type Progress struct {
+	Pages  int
+	Listed int
+	// LastID is the ID of the last listed repository.
+	LastID       int
+	Enriched     int
+	EnrichErrors int
+}
+
+// Watchable is implemented by reports that can report the progress of
+// their run, e.g. to display it.
+type Watchable interface {
+	// SetProgress sets the func the progress is reported to, instead of
+	// being printed. It is called from the goroutines of the run, one at
+	// a time.
+	SetProgress(func(Progress))
+}
+
+// progressTracker reports the progress of a run to a func, when there is
+// one.
+type progressTracker struct {
+	mu       sync.Mutex
+	progress Progress
+	report   func(Progress)
+}
+
+func (t *progressTracker) update(f func(*Progress)) {
+	if t.report == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f(&t.progress)
+	t.report(t.progress)
+}
+
+// Staged is implemented by reports run as a pipeline, to report the
+// metrics of its stages.
+type Staged interface {
+	Stats() PipelineStats
+}
+
+// enriched is the outcome of enriching the listed repository at index.
+type enriched struct {
+	index  int
+	record repoRecord
+	err    error
+}
+
+// crawl lists the repositories of the report's query and enriches them with
+// `workers` concurrent requests, passing each record to `aggregate` as soon
+// as it is enriched. Listed repositories only wait in a buffer as long as the
+// workers, so the listing slows down to their pace instead of piling up.
+//
+// The listed repositories, records and errors are kept on the report, the
+// records in listing order.
+func (r *report) crawl(ctx context.Context, gh *github.Github, workers int, aggregate func(repoRecord)) error {
+	if workers < 1 {
+		workers = DefaultConcurrency
+	}
+	start := time.Now()
+	stats := PipelineStats{Workers: workers}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type listedRepo struct {
+		index int
+		repo  github.Repos
+	}
+	listed := make(chan listedRepo, workers)
+	results := make(chan enriched, workers)
+	tracker := &progressTracker{report: r.progress}
+
+	var listErr error
+	go func() {
+		defer close(listed)
+		it := gh.IterateRepos(ctx, r.query)
+		for it.Next() {
+			if it.Pages() != stats.Pages {
+				stats.Pages = it.Pages()
+				r.printf("Listing page %d, from repository ID %d...\n", stats.Pages, it.Repo().ID)
+			}
+
+			repo := listedRepo{index: len(r.listed), repo: it.Repo()}
+			r.listed = append(r.listed, repo.repo)
+			tracker.update(func(p *Progress) {
+				p.Pages = it.Pages()
+				p.Listed++
+				p.LastID = repo.repo.ID
+			})
+
+			sent := time.Now()
+			select {
+			case listed <- repo:
+			case <-ctx.Done():
+				return
+			}
+			stats.ListBlocked += time.Since(sent)
+		}
+		stats.Pages = it.Pages()
+		stats.ListTime = time.Since(start)
+		if listErr = it.Err(); listErr != nil {
+			// Stops the enrichment, the report can't be complete.
+			cancel()
+		}
+	}()
+
+	var wg sync.WaitGroup
+	busy := make([]time.Duration, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for l := range listed {
+				begin := time.Now()
+				record, err := enrichRepo(ctx, gh, l.repo)
+				busy[w] += time.Since(begin)
+				results <- enriched{index: l.index, record: record, err: err}
+			}
+		}(w)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var done []enriched
+	for res := range results {
+		if res.err != nil {
+			stats.EnrichErrors++
+			r.aggregatedErrors = append(r.aggregatedErrors, res.err)
+			tracker.update(func(p *Progress) { p.EnrichErrors++ })
+			continue
+		}
+		stats.Enriched++
+		tracker.update(func(p *Progress) { p.Enriched++ })
+		if ctx.Err() == nil {
+			aggregate(res.record)
+			stats.Aggregated++
+		}
+		done = append(done, res)
+	}
+
+	// The listing goroutine has returned once the workers are done.
+	if listErr != nil {
+		return listErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(done, func(i, j int) bool { return done[i].index < done[j].index })
+	r.records = make([]repoRecord, len(done))
+	for i := range done {
+		r.records[i] = done[i].record
+	}
+	r.repoCount = len(r.listed)
+
+	for _, b := range busy {
+		stats.EnrichBusy += b
+	}
+	stats.Listed = len(r.listed)
+	stats.Elapsed = time.Since(start)
+	r.stats = stats
+
+	r.printf("Listed %d repositories in %d pages, and enriched %d of them with %d workers in %s.\n\n",
+		stats.Listed, stats.Pages, stats.Enriched, stats.Workers, stats.Elapsed.Round(time.Millisecond))
+	return nil
+}
+
+// printUnretrieved prints how many of the listed repositories couldn't be
+// retrieved, which the report doesn't count.
+func (r *report) printUnretrieved() {
+	if n := len(r.listed) - len(r.records); n > 0 {
+		fmt.Printf("\n%d of the %d listed repositories couldn't be retrieved, and aren't counted.", n, len(r.listed))
+	}
+}
+
+// printf prints the progress of the run to stderr, not to mix it with the
+// report written to stdout, unless it is reported to a progress func
+// instead.
+func (r *report) printf(format string, a ...interface{}) {
+	if r.progress == nil {
+		fmt.Fprintf(os.Stderr, format, a...)
+	}
+}
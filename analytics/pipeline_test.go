@@ -0,0 +1,106 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/githubtest"
+)
+
+func newFake(t *testing.T, count int) *githubtest.Server {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 7, Count: count, FirstID: 1000, MaxGap: 3, Owners: 12}))
+	server.PageSize = 10
+	t.Cleanup(server.Close)
+	return server
+}
+
+// Test_crawl asserts that the pipeline aggregates every repository of the
+// range once, whatever the number of workers, and keeps the records in
+// listing order.
+func Test_crawl(t *testing.T) {
+	server := newFake(t, 95)
+	repos := server.Repos()
+	query := github.Query{Since: 999, MaxID: repos[len(repos)-1].ID}
+
+	for _, workers := range []int{1, 3, 16} {
+		r := report{query: query}
+		stars := make(map[int]int)
+		err := r.crawl(context.Background(), server.Github(), workers, func(rec repoRecord) {
+			stars[rec.repo.ID] = rec.stars()
+		})
+		require.NoError(t, err)
+
+		require.Len(t, stars, len(repos))
+		require.Len(t, r.records, len(repos))
+		require.Equal(t, len(repos), r.repoCount)
+		for i, repo := range repos {
+			require.Equal(t, repo.StargazersCount, stars[repo.ID])
+			require.Equal(t, repo.ID, r.records[i].repo.ID)
+			require.Equal(t, repo.License, r.records[i].repo.License)
+		}
+
+		require.Equal(t, PipelineStats{
+			Listed:     len(repos),
+			Pages:      10,
+			Workers:    workers,
+			Enriched:   len(repos),
+			Aggregated: len(repos),
+		}, withoutDurations(r.stats))
+	}
+}
+
+func Test_crawlEnrichErrors(t *testing.T) {
+	server := newFake(t, 20)
+	repos := server.Repos()
+	server.FailNext("/repos/"+repos[4].FullName, http.StatusNotFound)
+
+	r := report{query: github.Query{Since: 999, MaxID: repos[len(repos)-1].ID}}
+	aggregated := 0
+	err := r.crawl(context.Background(), server.Github(), 4, func(repoRecord) { aggregated++ })
+	require.NoError(t, err)
+
+	require.Equal(t, len(repos)-1, aggregated)
+	require.Len(t, r.aggregatedErrors, 1)
+	require.Len(t, r.listed, len(repos))
+	require.Equal(t, 1, r.stats.EnrichErrors)
+	require.Equal(t, repos[5].ID, r.records[4].repo.ID)
+}
+
+func Test_crawlListingError(t *testing.T) {
+	server := newFake(t, 20)
+	server.FailNext("/repositories", http.StatusNotFound)
+
+	r := report{query: github.Query{Since: 999, MaxID: 2000}}
+	err := r.crawl(context.Background(), server.Github(), 4, func(repoRecord) {
+		t.Fatal("nothing should be aggregated")
+	})
+	require.Error(t, err)
+	require.Zero(t, server.Requests("/repos/"+server.Repos()[0].FullName))
+}
+
+func Test_crawlCanceled(t *testing.T) {
+	server := newFake(t, 50)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r := report{query: github.Query{Since: 999, MaxID: 2000}}
+	aggregated := 0
+	err := r.crawl(ctx, server.Github(), 2, func(repoRecord) {
+		aggregated++
+		if aggregated == 5 {
+			cancel()
+		}
+	})
+	require.True(t, errors.Is(err, context.Canceled), err)
+	// The listing stops too, instead of going through the whole range.
+	require.Less(t, server.Requests("/repositories"), 5)
+}
+
+func withoutDurations(s PipelineStats) PipelineStats {
+	s.ListBlocked, s.ListTime, s.EnrichBusy, s.Elapsed = 0, 0, 0, 0
+	return s
+}
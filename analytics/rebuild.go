@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"fmt"
+)
+
+// aggregating is implemented by the reports aggregated from the records of
+// the pipeline, which can be aggregated again from saved results.
+type aggregating interface {
+	// aggregator returns the func aggregating a record, and the one
+	// building the sorted aggregates once all the records are.
+	aggregator() (add func(repoRecord), done func())
+	base() *report
+}
+
+// Rebuild returns the report the results are of, aggregated again from
+// their enriched repositories, without fetching anything. It is sorted as
+// set in the options of the results.
+func Rebuild(results Results) (StatsReport, error) {
+	report, err := NewReport(results.ReportType, results.Options)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := report.(aggregating)
+	if !ok {
+		return nil, fmt.Errorf("the %s can't be rebuilt from its results", report.Name())
+	}
+
+	base := r.base()
+	base.listed = results.Listed
+	base.repoCount = len(results.Listed)
+	add, done := r.aggregator()
+	for _, repo := range results.Enriched {
+		record := repoRecord{repo: repo}
+		base.records = append(base.records, record)
+		add(record)
+	}
+	done()
+	base.stats = PipelineStats{
+		Listed:       len(results.Listed),
+		Enriched:     len(results.Enriched),
+		EnrichErrors: len(results.Listed) - len(results.Enriched),
+		Aggregated:   len(results.Enriched),
+	}
+
+	return report, nil
+}
@@ -0,0 +1,40 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRebuild asserts that a report rebuilt from the results of a run is
+// the same as the report of the run, once sorted the same way.
+func TestRebuild(t *testing.T) {
+	server := newFake(t, 40)
+	repos := server.Repos()
+	opts := ParamOptions{Since: 999, MaxID: repos[len(repos)-1].ID, Column: repoCol}
+
+	for _, reportType := range []string{StarGazersReportType, LicenseReportType, OwnerReportType, CrossTabReportType} {
+		run, err := NewReport(reportType, opts)
+		require.NoError(t, err)
+		run.(Watchable).SetProgress(func(Progress) {})
+		require.NoError(t, run.Run(context.Background(), server.Github()))
+		requests := server.TotalRequests()
+
+		results := run.(Recorder).Results()
+		rebuilt, err := Rebuild(results)
+		require.NoError(t, err)
+		require.Equal(t, requests, server.TotalRequests())
+		require.Equal(t, run.Count(), rebuilt.Count())
+		require.Equal(t, len(repos), rebuilt.(Staged).Stats().Listed)
+
+		// The first columns have no ties, which could be sorted either way.
+		first := run.(Sortable).Columns()[0]
+		require.NoError(t, run.(Sortable).Sort(first, true))
+		require.NoError(t, rebuilt.(Sortable).Sort(first, true))
+		require.Equal(t, run.(Tabular).Table().Render(), rebuilt.(Tabular).Table().Render())
+	}
+
+	_, err := Rebuild(Results{ReportType: LicenseReportType, Options: ParamOptions{Since: 1, MaxID: 10, Column: "stars"}})
+	require.EqualError(t, err, `"stars" is not a column of this report`)
+}
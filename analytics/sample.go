@@ -0,0 +1,377 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+const (
+	sampledReportNameFmt = "Sampled %s (estimate)"
+
+	categoryCol = "category"
+	shareCol    = "share"
+
+	// maxSamples and maxSampledIDs keep a sampled report within a single
+	// hour of the authenticated rate limit, 5000 requests: each sampled ID
+	// costs at most a request for its repository, plus a listing request
+	// per page of repositories.
+	maxSamples    = 40
+	maxSampledIDs = 4000
+)
+
+// SampleOptions configures a sampled report. Instead of crawling every ID in
+// the range, `Samples` windows of `Window` consecutive IDs are drawn at random
+// from it, and the results are extrapolated to the whole range.
+type SampleOptions struct {
+	Samples    int
+	Window     int
+	Confidence float64
+	Seed       int64
+}
+
+// SampleReport estimates the star bucket or license type distribution of the
+// repositories in a large ID range from random samples of it.
+//
+// Each sample is the set of repositories listed for one window, so the
+// samples are clusters of repositories rather than independent repositories.
+// The shares are estimated with a ratio estimator and their confidence
+// intervals use its linearized (cluster robust) variance.
+type SampleReport struct {
+	ParamOptions  ParamOptions
+	SampleOptions SampleOptions
+	report        report
+	reportType    string
+	estimates     []estimate
+	meanStars     estimate
+	// failed is the number of sampled repositories that couldn't be
+	// retrieved, which aren't counted in the samples.
+	failed int
+}
+
+type estimate struct {
+	category  string
+	repoCount int
+	value     float64
+	low, high float64
+	// ok is false when there are too few samples, or repositories,
+	// to estimate the variance.
+	ok bool
+}
+
+// NewSampleReport returns a report that samples the ID range in `opts`
+// instead of crawling all of it.
+func NewSampleReport(reportType string, opts ParamOptions, sample SampleOptions) (StatsReport, error) {
+	if err := validateSample(opts.Since, opts.MaxID, sample); err != nil {
+		return nil, err
+	}
+	if opts.Column != "" && sampleColumn(opts.Column) == "" {
+		return nil, fmt.Errorf("%q is not a column of this report", opts.Column)
+	}
+
+	var name string
+	switch reportType {
+	case StarGazersReportType:
+		name = starGazersReportName
+	case LicenseReportType:
+		name = licenseTypesReportName
+	default:
+		return nil, errors.New("only the stars and licenses reports can be sampled")
+	}
+
+	return &SampleReport{
+		ParamOptions:  opts,
+		SampleOptions: sample,
+		reportType:    reportType,
+		report: report{
+			name: fmt.Sprintf(sampledReportNameFmt, name),
+		},
+	}, nil
+}
+
+func (s *SampleReport) Run(ctx context.Context, gh *github.Github) error {
+	r := rand.New(rand.NewSource(s.SampleOptions.Seed))
+	span := s.ParamOptions.MaxID - s.ParamOptions.Since - s.SampleOptions.Window
+
+	// One row per sample, one column per category.
+	counts := make([]map[string]float64, s.SampleOptions.Samples)
+	stars := make([]float64, s.SampleOptions.Samples)
+	sizes := make([]float64, s.SampleOptions.Samples)
+	categories := make(map[string]int)
+
+	for i := 0; i < s.SampleOptions.Samples; i++ {
+		since := s.ParamOptions.Since + r.Intn(span+1)
+		fmt.Fprintf(os.Stderr, "Sample %d of %d: repositories with IDs from %d to %d\n",
+			i+1, s.SampleOptions.Samples, since+1, since+s.SampleOptions.Window)
+
+		sample, err := s.crawlSample(ctx, gh, github.Query{Since: since, MaxID: since + s.SampleOptions.Window})
+		if err != nil {
+			return err
+		}
+		counts[i], sizes[i], stars[i] = sample.counts, float64(sample.size), float64(sample.stars)
+		for category, repoCount := range sample.counts {
+			categories[category] += int(repoCount)
+		}
+	}
+
+	z := zScore(s.SampleOptions.Confidence)
+	s.estimates = make([]estimate, 0, len(categories))
+	for category, repoCount := range categories {
+		y := make([]float64, len(counts))
+		for i := range counts {
+			y[i] = counts[i][category]
+		}
+
+		e := ratioEstimate(y, sizes, z, 1)
+		e.category = category
+		e.repoCount = repoCount
+		s.estimates = append(s.estimates, e)
+	}
+	if s.reportType == StarGazersReportType {
+		s.meanStars = ratioEstimate(stars, sizes, z, math.Inf(1))
+	}
+
+	s.sort()
+
+	return nil
+}
+
+// sample holds the retrieved repositories of a sample window.
+type sample struct {
+	// counts is the number of repositories per category.
+	counts map[string]float64
+	size   int
+	stars  int
+}
+
+// crawlSample crawls the repositories of a sample window like the crawled
+// reports do, and counts them with the aggregator of the sampled report.
+// The repositories that couldn't be retrieved are left out of the sample,
+// and those without a license are counted as noLicense.
+func (s *SampleReport) crawlSample(ctx context.Context, gh *github.Github, query github.Query) (sample, error) {
+	r := report{query: query, progress: s.report.progress}
+	counted := sample{counts: make(map[string]float64)}
+
+	var add func(repoRecord)
+	var done func()
+	var count func()
+	switch s.reportType {
+	case StarGazersReportType:
+		b := &BucketReport{}
+		add, done = b.aggregator()
+		count = func() {
+			for _, bucket := range b.aggregate {
+				counted.counts[bucket.bucket] = float64(bucket.repoCount)
+				counted.stars += bucket.starCount
+			}
+		}
+	case LicenseReportType:
+		l := &LicenseTypeReport{}
+		add, done = l.aggregator()
+		count = func() {
+			for _, license := range l.aggregate {
+				counted.counts[license.license] = float64(license.repoCount)
+			}
+			if l.unlicensed > 0 {
+				counted.counts[noLicense] = float64(l.unlicensed)
+			}
+		}
+	}
+
+	if err := r.crawl(ctx, gh, s.ParamOptions.Concurrency, add); err != nil {
+		return sample{}, err
+	}
+	done()
+	count()
+
+	counted.size = len(r.records)
+	s.report.repoCount += counted.size
+	s.failed += len(r.listed) - len(r.records)
+	s.report.aggregatedErrors = append(s.report.aggregatedErrors, r.aggregatedErrors...)
+	return counted, nil
+}
+
+func (s *SampleReport) Count() int {
+	return s.report.repoCount
+}
+
+func (s *SampleReport) Name() string {
+	return s.report.name
+}
+
+// Columns returns the columns the report can be sorted by.
+func (s *SampleReport) Columns() []string {
+	return []string{categoryCol, shareCol}
+}
+
+// SortedBy returns the column, and the order, the report is sorted by.
+func (s *SampleReport) SortedBy() (string, bool) {
+	return sortColumn(s.ParamOptions.Column, s.Columns()), s.ParamOptions.Asc
+}
+
+// Sort sorts the estimates again, by one of Columns.
+func (s *SampleReport) Sort(column string, asc bool) error {
+	if sampleColumn(column) == "" {
+		return fmt.Errorf("%q is not a column of this report", column)
+	}
+	s.ParamOptions.Column, s.ParamOptions.Asc = column, asc
+	s.sort()
+	return nil
+}
+
+func (s *SampleReport) sort() {
+	estimates := s.estimates
+	s.ParamOptions.Column = sampleColumn(s.ParamOptions.Column)
+	sort.Slice(estimates, func(i, j int) bool {
+		var res bool
+		switch s.ParamOptions.Column {
+		case shareCol:
+			res = estimates[i].value < estimates[j].value
+		default:
+			res = estimates[i].category < estimates[j].category
+		}
+
+		if !s.ParamOptions.Asc {
+			return !res
+		}
+		return res
+	})
+}
+
+func (s *SampleReport) PrintStats() {
+	fmt.Println("Printing a sampled report...")
+	fmt.Println("Ordering by column: ", s.ParamOptions.Column)
+	fmt.Printf("Sorting by asc?: %v\n\n", s.ParamOptions.Asc)
+
+	fmt.Printf("ESTIMATE from %d random samples of %d IDs between %d and %d:\n%s\n",
+		s.SampleOptions.Samples, s.SampleOptions.Window, s.ParamOptions.Since, s.ParamOptions.MaxID, s.Table().Render())
+
+	if s.failed > 0 {
+		fmt.Printf("%d of the sampled repositories couldn't be retrieved, and aren't counted.\n", s.failed)
+	}
+	if s.reportType == StarGazersReportType {
+		fmt.Printf("Estimated average stars/repo: %.2f (%s: %s)\n",
+			s.meanStars.value, s.confidence(), s.meanStars.interval(func(v float64) string {
+				return fmt.Sprintf("%.2f", v)
+			}))
+	}
+}
+
+func (s *SampleReport) confidence() string {
+	return fmt.Sprintf("%g%% ci", s.SampleOptions.Confidence*100)
+}
+
+// Table returns the table of the estimates, in sort order.
+func (s *SampleReport) Table() table.Writer {
+	category := bucketCol
+	if s.reportType == LicenseReportType {
+		category = licenseCol
+	}
+
+	tw := table.NewWriter()
+	tw.AppendHeader(table.Row{category, "sampled #repos", "estimated share", s.confidence()})
+
+	for _, e := range s.estimates {
+		tw.AppendRows([]table.Row{
+			{e.category, e.repoCount, percent(e.value), e.interval(percent)},
+		})
+	}
+
+	tw.AppendFooter(table.Row{"total", s.report.repoCount, "", ""})
+	tw.SetStyle(table.StyleRounded)
+	tw.Style().Format.Header = text.FormatLower
+	tw.Style().Format.Row = text.FormatLower
+	tw.Style().Format.Footer = text.FormatLower
+	return tw
+}
+
+func (e estimate) interval(format func(float64) string) string {
+	if !e.ok {
+		return "n/a"
+	}
+	return format(e.low) + " - " + format(e.high)
+}
+
+func percent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v*100)
+}
+
+func sampleColumn(key string) string {
+	return map[string]string{
+		"1":         categoryCol,
+		"2":         shareCol,
+		categoryCol: categoryCol,
+		shareCol:    shareCol,
+	}[key]
+}
+
+// ratioEstimate estimates sum(y)/sum(x) from per sample totals, with a
+// confidence interval of `z` standard errors clamped to [0, max]. The
+// standard error is the linearized variance of the ratio estimator:
+//
+//	var = sum((y_i - r*x_i)^2) / (k * (k-1) * mean(x)^2)
+func ratioEstimate(y, x []float64, z, max float64) estimate {
+	var sumY, sumX float64
+	for i := range y {
+		sumY += y[i]
+		sumX += x[i]
+	}
+	if sumX == 0 {
+		return estimate{}
+	}
+
+	r := sumY / sumX
+	k := float64(len(y))
+	if k < 2 {
+		return estimate{value: r}
+	}
+
+	var ss float64
+	for i := range y {
+		d := y[i] - r*x[i]
+		ss += d * d
+	}
+	meanX := sumX / k
+	se := math.Sqrt(ss/(k*(k-1))) / meanX
+
+	return estimate{
+		value: r,
+		low:   math.Max(0, r-z*se),
+		high:  math.Min(max, r+z*se),
+		ok:    true,
+	}
+}
+
+// zScore returns the two sided critical value of the standard normal
+// distribution for the given confidence level.
+func zScore(confidence float64) float64 {
+	return math.Sqrt2 * math.Erfinv(confidence)
+}
+
+func validateSample(since, max int, sample SampleOptions) error {
+	switch {
+	case sample.Samples < 2:
+		return fmt.Errorf("at least 2 samples are needed to estimate a confidence interval, got %d", sample.Samples)
+	case sample.Samples > maxSamples:
+		return fmt.Errorf("the number of samples (%d) has exceeded the limit (%d)", sample.Samples, maxSamples)
+	case sample.Window < 1:
+		return fmt.Errorf("the sample window must be at least 1 ID, got %d", sample.Window)
+	case sample.Window > maxSampledIDs || sample.Samples*sample.Window > maxSampledIDs:
+		return fmt.Errorf("the samples span %d IDs (%d of %d), more than the limit (%d)",
+			sample.Samples*sample.Window, sample.Samples, sample.Window, maxSampledIDs)
+	case sample.Confidence <= 0 || sample.Confidence >= 1:
+		return fmt.Errorf("the confidence level must be between 0 and 1, got %g", sample.Confidence)
+	case max-since < sample.Window:
+		return fmt.Errorf("the ID range (%d to %d) is smaller than the sample window (%d)", since, max, sample.Window)
+	}
+	return nil
+}
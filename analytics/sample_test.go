@@ -0,0 +1,149 @@
+package analytics
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+func Test_zScore(t *testing.T) {
+	require.InDelta(t, 1.645, zScore(0.90), 0.001)
+	require.InDelta(t, 1.960, zScore(0.95), 0.001)
+	require.InDelta(t, 2.576, zScore(0.99), 0.001)
+}
+
+func Test_ratioEstimate(t *testing.T) {
+	tc := []struct {
+		name     string
+		y, x     []float64
+		max      float64
+		expected estimate
+	}{
+		{
+			name:     "no repositories",
+			y:        []float64{0, 0},
+			x:        []float64{0, 0},
+			max:      1,
+			expected: estimate{},
+		},
+		{
+			name:     "single sample has no interval",
+			y:        []float64{3},
+			x:        []float64{10},
+			max:      1,
+			expected: estimate{value: 0.3},
+		},
+		{
+			name:     "identical samples have no variance",
+			y:        []float64{5, 5, 5},
+			x:        []float64{10, 10, 10},
+			max:      1,
+			expected: estimate{value: 0.5, low: 0.5, high: 0.5, ok: true},
+		},
+		{
+			// r = 6/20, residuals are +1 and -1, var = 2/(2*1*10^2) = 0.01
+			name:     "two samples",
+			y:        []float64{4, 2},
+			x:        []float64{10, 10},
+			max:      1,
+			expected: estimate{value: 0.3, low: 0.2, high: 0.4, ok: true},
+		},
+		{
+			name:     "interval is clamped to zero",
+			y:        []float64{0, 2},
+			x:        []float64{10, 10},
+			max:      1,
+			expected: estimate{value: 0.1, low: 0, high: 0.2, ok: true},
+		},
+		{
+			name:     "interval is clamped to the maximum",
+			y:        []float64{10, 8},
+			x:        []float64{10, 10},
+			max:      1,
+			expected: estimate{value: 0.9, low: 0.8, high: 1, ok: true},
+		},
+		{
+			name:     "unbounded maximum",
+			y:        []float64{40, 20},
+			x:        []float64{10, 10},
+			max:      math.Inf(1),
+			expected: estimate{value: 3, low: 2, high: 4, ok: true},
+		},
+	}
+
+	for _, tc := range tc {
+		t.Run(tc.name, func(t *testing.T) {
+			e := ratioEstimate(tc.y, tc.x, 1, tc.max)
+
+			require.Equal(t, tc.expected.ok, e.ok)
+			require.InDelta(t, tc.expected.value, e.value, 1e-9)
+			require.InDelta(t, tc.expected.low, e.low, 1e-9)
+			require.InDelta(t, tc.expected.high, e.high, 1e-9)
+		})
+	}
+}
+
+// TestSampleReport asserts that the sampled repositories are counted as
+// the crawled reports count them: the ones without a license as "none",
+// and the ones that couldn't be retrieved left out of the samples.
+func TestSampleReport(t *testing.T) {
+	server := newFake(t, 30)
+	repos := server.Repos()
+	last := repos[len(repos)-1].ID
+	// Each sample is the whole range.
+	sample := SampleOptions{Samples: 2, Window: last - 999, Confidence: 0.95, Seed: 1}
+
+	for _, reportType := range []string{StarGazersReportType, LicenseReportType} {
+		server.FailNext("/repos/"+repos[3].FullName, http.StatusNotFound)
+		server.FailNext("/repos/"+repos[3].FullName, http.StatusNotFound)
+		report, err := NewSampleReport(reportType, ParamOptions{Since: 999, MaxID: last}, sample)
+		require.NoError(t, err)
+		require.NoError(t, report.Run(context.Background(), server.Github()))
+		s := report.(*SampleReport)
+
+		expected := make(map[string]int)
+		for i, repo := range repos {
+			switch {
+			case i == 3:
+			case reportType == StarGazersReportType:
+				expected[github.BucketTier(repo.StargazersCount)]++
+			case repo.License.Name == "":
+				expected[noLicense]++
+			default:
+				expected[repo.License.Name]++
+			}
+		}
+
+		shares := make(map[string]float64)
+		for category, repoCount := range expected {
+			shares[category] = float64(repoCount) / float64(len(repos)-1)
+		}
+		estimated := make(map[string]float64)
+		sampled := make(map[string]int)
+		for _, e := range s.estimates {
+			estimated[e.category] = e.value
+			sampled[e.category] = e.repoCount / 2
+		}
+		require.Equal(t, expected, sampled, reportType)
+		require.InDeltaMapValues(t, shares, estimated, 1e-9, reportType)
+		require.Equal(t, 2*(len(repos)-1), s.Count())
+		require.Equal(t, 2, s.failed)
+	}
+}
+
+func Test_validateSample(t *testing.T) {
+	valid := SampleOptions{Samples: 40, Window: 100, Confidence: 0.95}
+	require.NoError(t, validateSample(0, 1000, valid))
+
+	tooLong := valid
+	tooLong.Window = 101
+	require.EqualError(t, validateSample(0, 1000, tooLong), "the samples span 4040 IDs (40 of 101), more than the limit (4000)")
+
+	tooLong.Samples, tooLong.Window = 2, 1<<62
+	require.Error(t, validateSample(0, 1<<63-1, tooLong))
+}
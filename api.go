@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/carlisia/ghinfo/config"
+	"github.com/carlisia/ghinfo/github"
+)
+
+// The environment variables selecting the profile and the GitHub instance,
+// when the flags aren't set.
+const (
+	profileEnv    = "GHINFO_PROFILE"
+	baseURLEnv    = "GH_BASE_URL"
+	graphQLURLEnv = "GH_GRAPHQL_URL"
+	caBundleEnv   = "GH_CA_BUNDLE"
+)
+
+// apiFlags select the profile of the configuration file, and the GitHub
+// instance to call, github.com by default. Each setting is taken from its
+// flag, then its environment variable, then the profile.
+type apiFlags struct {
+	profile    string
+	baseURL    string
+	graphQLURL string
+	caBundle   string
+}
+
+func (a *apiFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&a.profile, "profile", "", "profile of the configuration file to use (default $"+profileEnv+", or the default_profile of the file)")
+	fs.StringVar(&a.baseURL, "base-url", "", "GitHub API URL, or the URL of a GitHub Enterprise Server (default $"+baseURLEnv+" or "+github.DefaultBaseURL+")")
+	fs.StringVar(&a.graphQLURL, "graphql-url", "", "GitHub GraphQL API URL (default $"+graphQLURLEnv+", or derived from the API URL)")
+	fs.StringVar(&a.caBundle, "ca-bundle", "", "PEM file of certificate authorities to trust, e.g. for a GitHub Enterprise Server (default $"+caBundleEnv+")")
+}
+
+// apiSettings are the resolved apiFlags.
+type apiSettings struct {
+	baseURL string
+	// graphQLURL is derived from baseURL when empty.
+	graphQLURL string
+	httpClient *http.Client
+	// tokenSource is one of config.TokenSources, auto when empty.
+	tokenSource string
+}
+
+// loadProfile returns the selected profile of the configuration file.
+func (a apiFlags) loadProfile() (config.Profile, error) {
+	file, err := config.LoadDefault()
+	if err != nil {
+		return config.Profile{}, err
+	}
+	return file.Select(firstSet(a.profile, os.Getenv(profileEnv)))
+}
+
+// resolve returns the settings of the flags, falling back on `profile`,
+// the one loadProfile returned.
+func (a apiFlags) resolve(profile config.Profile) (apiSettings, error) {
+	baseURL := firstSet(a.baseURL, os.Getenv(baseURLEnv), profile.BaseURL, github.DefaultBaseURL)
+	baseURL, err := github.NormalizeBaseURL(baseURL)
+	if err != nil {
+		return apiSettings{}, fmt.Errorf("invalid GitHub API URL: %w", err)
+	}
+	graphQLURL := firstSet(a.graphQLURL, os.Getenv(graphQLURLEnv), profile.GraphQLURL)
+	if graphQLURL != "" {
+		if graphQLURL, err = github.NormalizeGraphQLURL(graphQLURL); err != nil {
+			return apiSettings{}, fmt.Errorf("invalid GitHub GraphQL API URL: %w", err)
+		}
+	}
+
+	settings := apiSettings{
+		baseURL:     baseURL,
+		graphQLURL:  graphQLURL,
+		httpClient:  http.DefaultClient,
+		tokenSource: profile.TokenSource,
+	}
+	if caBundle := firstSet(a.caBundle, os.Getenv(caBundleEnv), profile.CABundle); caBundle != "" {
+		if settings.httpClient, err = caClient(caBundle); err != nil {
+			return apiSettings{}, err
+		}
+	}
+	return settings, nil
+}
+
+// github returns a client of the API sending its requests with client.
+func (s apiSettings) github(client github.HTTPClient) (*github.Github, error) {
+	gh, err := github.New(client, s.baseURL, userAgent)
+	if err != nil {
+		return nil, err
+	}
+	if s.graphQLURL != "" {
+		gh.SetGraphQLURL(s.graphQLURL)
+	}
+	return gh, nil
+}
+
+// caClient returns an HTTP client trusting the certificate authorities
+// of the PEM file at path, along with the system ones.
+func caClient(path string) (*http.Client, error) {
+	pem, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to read the CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate could be read from the CA bundle %s", path)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Transport: transport}, nil
+}
+
+// firstSet returns the first non empty value.
+func firstSet(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/config"
+)
+
+// TestAPIFlagsGraphQLURL asserts that the GraphQL API URL is taken from
+// the flag, then the environment, then the profile, derived from the API
+// URL when none is set, and set on the client.
+func TestAPIFlagsGraphQLURL(t *testing.T) {
+	dir := t.TempDir()
+	setenv(t, "XDG_CONFIG_HOME", dir)
+	setenv(t, graphQLURLEnv, "")
+	setenv(t, baseURLEnv, "")
+	setenv(t, profileEnv, "")
+
+	graphQLURL := func(flags apiFlags) string {
+		profile, err := flags.loadProfile()
+		require.NoError(t, err)
+		settings, err := flags.resolve(profile)
+		require.NoError(t, err)
+		gh, err := settings.github(nil)
+		require.NoError(t, err)
+		return gh.GraphQLURL()
+	}
+
+	require.Equal(t, "https://api.github.com/graphql", graphQLURL(apiFlags{}))
+	require.Equal(t, "https://github.example.com/api/graphql", graphQLURL(apiFlags{baseURL: "https://github.example.com"}))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "ghinfo"), 0o755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ghinfo", "config.yaml"), []byte("graphql_url: https://profile.example.com/graphql/\n"), 0o644))
+	require.Equal(t, "https://profile.example.com/graphql", graphQLURL(apiFlags{}))
+
+	setenv(t, graphQLURLEnv, "https://env.example.com/graphql")
+	require.Equal(t, "https://env.example.com/graphql", graphQLURL(apiFlags{}))
+	require.Equal(t, "https://flag.example.com/graphql", graphQLURL(apiFlags{graphQLURL: "https://flag.example.com/graphql"}))
+
+	_, err := apiFlags{graphQLURL: "flag.example.com/graphql"}.resolve(config.Profile{})
+	require.EqualError(t, err, `invalid GitHub GraphQL API URL: "flag.example.com/graphql" is not an http(s) URL`)
+}
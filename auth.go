@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+
+	"github.com/carlisia/ghinfo/credentials"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/githubapp"
+	"github.com/carlisia/ghinfo/github/tokenpool"
+)
+
+// The environment variables listing several personal access tokens, whose
+// rate limits are used one after the other.
+const (
+	gitHubTokens     = "GH_TOKENS"
+	gitHubTokensFile = "GH_TOKENS_FILE"
+)
+
+// The environment variables authenticating as a GitHub App installation,
+// instead of with a personal access token.
+const (
+	appIDEnv             = "GH_APP_ID"
+	appInstallationIDEnv = "GH_APP_INSTALLATION_ID"
+	// appPrivateKeyEnv is the path of the PEM private key of the app,
+	// or the key itself.
+	appPrivateKeyEnv = "GH_APP_PRIVATE_KEY"
+)
+
+// authClient returns an HTTP client of the API of `api`, authenticated
+// with the credentials of its token source. With the auto source, those
+// are the first set of: the installation tokens of a GitHub App, the pool
+// of tokens of GH_TOKENS or GH_TOKENS_FILE, and a token found by
+// credentials.Finder, starting with GH_TOKEN. Without any, the API is
+// called unauthenticated.
+func authClient(ctx context.Context, api apiSettings) (github.HTTPClient, error) {
+	source := api.tokenSource
+	if source == "" || source == "auto" {
+		switch {
+		case os.Getenv(appIDEnv) != "":
+			source = "app"
+		case os.Getenv(gitHubTokens) != "" || os.Getenv(gitHubTokensFile) != "":
+			source = "tokens"
+		}
+	}
+
+	var ts oauth2.TokenSource
+	switch source {
+	case "app":
+		var err error
+		if ts, err = appTokenSource(api); err != nil {
+			return nil, err
+		}
+	case "tokens":
+		return tokenPool(api)
+	case "none":
+		fmt.Fprintln(os.Stderr, "Calling the GitHub API unauthenticated, which only allows 60 requests per hour.")
+		return api.httpClient, nil
+	case "", "auto":
+		token, err := credentials.Finder{}.Find(ctx, webHost(api.baseURL))
+		if errors.Is(err, credentials.ErrNotFound) {
+			fmt.Fprint(os.Stderr, missingTokenMsg)
+			return api.httpClient, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error trying to find a GitHub token: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Using the GitHub token of %s.\n", token.Source)
+		ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token.Value})
+	default:
+		token, err := credentials.Finder{}.FindIn(ctx, webHost(api.baseURL), source)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Using the GitHub token of %s.\n", token.Source)
+		ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token.Value})
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, api.httpClient)
+	return oauth2.NewClient(ctx, ts), nil
+}
+
+// webHost returns the host credentials are usually kept for: github.com
+// for its API, and the host of a GitHub Enterprise Server.
+func webHost(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "api.github.com" {
+		return "github.com"
+	}
+	return u.Host
+}
+
+// tokenPool returns a pool of the tokens of GH_TOKENS and GH_TOKENS_FILE.
+func tokenPool(api apiSettings) (*tokenpool.Pool, error) {
+	tokens := tokenpool.ParseTokens(os.Getenv(gitHubTokens))
+	if path := os.Getenv(gitHubTokensFile); path != "" {
+		fromFile, err := tokenpool.ReadTokens(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", gitHubTokensFile, err)
+		}
+		tokens = append(tokens, fromFile...)
+	}
+
+	pool, err := tokenpool.New(api.httpClient, tokens...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s or %s: %w", gitHubTokens, gitHubTokensFile, err)
+	}
+	return pool, nil
+}
+
+func appTokenSource(api apiSettings) (oauth2.TokenSource, error) {
+	appID, err := strconv.ParseInt(os.Getenv(appIDEnv), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", appIDEnv, err)
+	}
+	installationID, err := strconv.ParseInt(os.Getenv(appInstallationIDEnv), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, the ID of the app installation is needed: %w", appInstallationIDEnv, err)
+	}
+
+	keyEnv := os.Getenv(appPrivateKeyEnv)
+	if keyEnv == "" {
+		return nil, fmt.Errorf("please set %s to the private key of the app", appPrivateKeyEnv)
+	}
+	var key *rsa.PrivateKey
+	if strings.HasPrefix(strings.TrimSpace(keyEnv), "-----BEGIN") {
+		key, err = githubapp.ParsePrivateKey([]byte(keyEnv))
+	} else {
+		key, err = githubapp.ReadPrivateKey(keyEnv)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", appPrivateKeyEnv, err)
+	}
+
+	return githubapp.NewTokenSource(githubapp.Config{
+		Client:         api.httpClient,
+		BaseURL:        api.baseURL,
+		UserAgent:      userAgent,
+		AppID:          appID,
+		InstallationID: installationID,
+		Key:            key,
+	})
+}
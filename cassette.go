@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/carlisia/ghinfo/config"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/cassette"
+)
+
+// cassetteFlags select the GitHub instance to call, and whether its
+// responses are recorded to, or played back from, a cassette directory.
+type cassetteFlags struct {
+	api    apiFlags
+	record string
+	replay string
+}
+
+func (c *cassetteFlags) register(fs *flag.FlagSet) {
+	c.api.register(fs)
+	fs.StringVar(&c.record, "record", "", "record the GitHub API responses to this directory")
+	fs.StringVar(&c.replay, "replay", "", "play back the GitHub API responses recorded to this directory, instead of calling the API; no token is needed")
+}
+
+// newGithub returns the GitHub client for the flags and `profile`, the one
+// c.api.loadProfile returned. When replaying, no request is sent, and so
+// no token is needed.
+func (c cassetteFlags) newGithub(ctx context.Context, profile config.Profile) (*github.Github, error) {
+	if c.record != "" && c.replay != "" {
+		return nil, errors.New("--record and --replay cannot be used together")
+	}
+	api, err := c.api.resolve(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.replay != "" {
+		player, err := cassette.NewPlayer(c.replay)
+		if err != nil {
+			return nil, err
+		}
+		return api.github(player)
+	}
+
+	if c.record == "" {
+		return newGithub(ctx, api)
+	}
+
+	client, err := authClient(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+	recorder, err := cassette.NewRecorder(client, c.record)
+	if err != nil {
+		return nil, err
+	}
+	return api.github(recorder)
+}
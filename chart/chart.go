@@ -0,0 +1,176 @@
+// Package chart draws the distributions of the repositories of a report as
+// an image, a pie chart of the license share next to a bar chart of the
+// star buckets, to be embedded in slides and READMEs.
+//
+// The charts are laid out once on a canvas, which either writes an SVG
+// document or rasterizes them to a PNG image, in pure Go.
+package chart
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/carlisia/ghinfo/analytics"
+)
+
+// The size of the image, in pixels.
+const (
+	width  = 1000
+	height = 420
+)
+
+// maxSlices is the number of slices of the pie chart: the least common
+// licenses are grouped in the last one.
+const maxSlices = 8
+
+// maxLabel is the number of characters the labels are cut to.
+const maxLabel = 34
+
+var (
+	// palette colors the slices of the pie chart.
+	palette = []color.RGBA{
+		{0x4e, 0x79, 0xa7, 0xff},
+		{0xf2, 0x8e, 0x2b, 0xff},
+		{0xe1, 0x57, 0x59, 0xff},
+		{0x76, 0xb7, 0xb2, 0xff},
+		{0x59, 0xa1, 0x4f, 0xff},
+		{0xed, 0xc9, 0x48, 0xff},
+		{0xb0, 0x7a, 0xa1, 0xff},
+		{0xba, 0xb0, 0xac, 0xff},
+	}
+	barColor   = palette[0]
+	textColor  = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	axisColor  = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+)
+
+// anchor is the point of a text its position is given for.
+type anchor int
+
+const (
+	anchorStart anchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+// canvas is what the charts are drawn on.
+type canvas interface {
+	rect(x, y, w, h float64, c color.RGBA)
+	// wedge fills the slice of the disc of center (cx, cy) and radius r
+	// between the angles from and to, in radians clockwise from noon.
+	wedge(cx, cy, r, from, to float64, c color.RGBA)
+	// text writes s with its baseline at y, and its start, middle or end
+	// at x.
+	text(x, y float64, s string, a anchor, bold bool, c color.RGBA)
+}
+
+// WriteSVG writes the charts of the distributions as an SVG document,
+// under the title.
+func WriteSVG(w io.Writer, title string, d analytics.Distributions) error {
+	s := newSVG()
+	layout(s, title, d)
+	return s.write(w)
+}
+
+// WritePNG writes the charts of the distributions as a PNG image, under
+// the title.
+func WritePNG(w io.Writer, title string, d analytics.Distributions) error {
+	p := newPNG()
+	layout(p, title, d)
+	return p.write(w)
+}
+
+// layout lays out the charts on the canvas: the pie chart on the left, and
+// the bar chart on the right.
+func layout(c canvas, title string, d analytics.Distributions) {
+	c.rect(0, 0, width, height, background)
+	c.text(width/2, 34, title, anchorMiddle, true, textColor)
+	drawPie(c, d.Licenses)
+	drawBars(c, d.Buckets)
+}
+
+// drawPie draws the share of the licenses, with the legend next to the
+// pie.
+func drawPie(c canvas, licenses []analytics.Share) {
+	const cx, cy, r = 140, 250, 120
+	const legendX, rowHeight = 285, 24
+
+	total := sum(licenses)
+	c.text(300, 80, fmt.Sprintf("License share of %d repositories", total), anchorMiddle, true, textColor)
+	if total == 0 {
+		c.text(cx, cy, "No repositories", anchorMiddle, false, axisColor)
+		return
+	}
+
+	slices := pieSlices(licenses)
+	y := cy - float64(len(slices)*rowHeight)/2 + 16
+	var from float64
+	for i, slice := range slices {
+		fill := palette[i%len(palette)]
+		to := from + 2*math.Pi*float64(slice.Count)/float64(total)
+		if slice.Count > 0 {
+			c.wedge(cx, cy, r, from, to, fill)
+		}
+		from = to
+
+		c.rect(legendX, y-11, 12, 12, fill)
+		label := fmt.Sprintf("%s: %d (%.1f%%)", cut(slice.Label), slice.Count, 100*float64(slice.Count)/float64(total))
+		c.text(legendX+20, y, label, anchorStart, false, textColor)
+		y += rowHeight
+	}
+}
+
+// pieSlices returns the licenses, grouping the least common ones in a
+// last slice when there are more than maxSlices.
+func pieSlices(licenses []analytics.Share) []analytics.Share {
+	if len(licenses) <= maxSlices {
+		return licenses
+	}
+	rest := licenses[maxSlices-1:]
+	grouped := analytics.Share{Label: fmt.Sprintf("%d other licenses", len(rest)), Count: sum(rest)}
+	return append(licenses[:maxSlices-1:maxSlices-1], grouped)
+}
+
+// drawBars draws the repositories per star bucket as horizontal bars.
+func drawBars(c canvas, buckets []analytics.Share) {
+	const axisX, top, rowHeight, barHeight, maxBar = 770, 110, 46, 28, 170
+
+	c.text(850, 80, "Repositories per star bucket", anchorMiddle, true, textColor)
+	c.rect(axisX-1, top-8, 1, float64(len(buckets)*rowHeight)+8, axisColor)
+
+	max := 0
+	for _, bucket := range buckets {
+		if bucket.Count > max {
+			max = bucket.Count
+		}
+	}
+	for i, bucket := range buckets {
+		y := float64(top + i*rowHeight)
+		var length float64
+		if max > 0 {
+			length = maxBar * float64(bucket.Count) / float64(max)
+		}
+		c.text(axisX-10, y+barHeight/2+5, cut(bucket.Label), anchorEnd, false, textColor)
+		c.rect(axisX, y, length, barHeight, barColor)
+		c.text(axisX+length+8, y+barHeight/2+5, fmt.Sprintf("%d", bucket.Count), anchorStart, false, textColor)
+	}
+}
+
+func sum(shares []analytics.Share) int {
+	total := 0
+	for _, share := range shares {
+		total += share.Count
+	}
+	return total
+}
+
+// cut cuts the label to maxLabel characters, so that it fits the layout.
+func cut(label string) string {
+	runes := []rune(label)
+	if len(runes) <= maxLabel {
+		return label
+	}
+	return string(runes[:maxLabel-3]) + "..."
+}
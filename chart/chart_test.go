@@ -0,0 +1,77 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/analytics"
+)
+
+var distributions = analytics.Distributions{
+	Buckets: []analytics.Share{
+		{Label: "0..10", Count: 7}, {Label: "10..100", Count: 2}, {Label: "100..1000", Count: 0},
+		{Label: "1000..5000", Count: 0}, {Label: "5000..10000", Count: 0}, {Label: ">=10000", Count: 1},
+	},
+	Licenses: []analytics.Share{{Label: "MIT License", Count: 6}, {Label: "none", Count: 3}, {Label: "BSD <3-Clause>", Count: 1}},
+}
+
+func TestWriteSVG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSVG(&buf, "Stargazers Report", distributions))
+
+	// The document is well formed.
+	d := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+	}
+
+	svg := buf.String()
+	require.Contains(t, svg, ">Stargazers Report</text>")
+	require.Contains(t, svg, ">License share of 10 repositories</text>")
+	require.Contains(t, svg, ">MIT License: 6 (60.0%)</text>")
+	require.Contains(t, svg, ">BSD &lt;3-Clause&gt;: 1 (10.0%)</text>")
+	require.Contains(t, svg, ">5000..10000</text>")
+	require.Equal(t, 3, bytes.Count(buf.Bytes(), []byte("<path ")))
+}
+
+func TestWritePNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WritePNG(&buf, "Stargazers Report", distributions))
+
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, width, img.Bounds().Dx())
+	require.Equal(t, height, img.Bounds().Dy())
+
+	// The most common license fills the pie from noon on, and the most
+	// common bucket has the longest bar.
+	require.Equal(t, palette[0], img.At(140+40, 250-60))
+	require.Equal(t, palette[1], img.At(140-60, 250-10))
+	require.Equal(t, barColor, img.At(770+160, 110+14))
+	require.Equal(t, background, img.At(770+160, 110+46+14))
+}
+
+func Test_pieSlices(t *testing.T) {
+	var licenses []analytics.Share
+	for i := 10; i > 0; i-- {
+		licenses = append(licenses, analytics.Share{Label: fmt.Sprintf("license %d", i), Count: i})
+	}
+
+	slices := pieSlices(licenses)
+	require.Len(t, slices, maxSlices)
+	require.Equal(t, licenses[:maxSlices-1], slices[:maxSlices-1])
+	require.Equal(t, analytics.Share{Label: "3 other licenses", Count: 3 + 2 + 1}, slices[maxSlices-1])
+	require.Equal(t, "license 3", licenses[maxSlices-1].Label)
+
+	require.Equal(t, licenses[:3], pieSlices(licenses[:3]))
+}
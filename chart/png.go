@@ -0,0 +1,75 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// wedgeStep is the angle of the segments the arcs of the wedges are
+// rasterized as, 2 degrees.
+const wedgeStep = math.Pi / 90
+
+// pngImage is a canvas rasterizing the charts to an image.
+type pngImage struct {
+	img *image.RGBA
+}
+
+func newPNG() *pngImage {
+	return &pngImage{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+}
+
+func (p *pngImage) write(w io.Writer) error {
+	return png.Encode(w, p.img)
+}
+
+func (p *pngImage) rect(x, y, w, h float64, c color.RGBA) {
+	r := image.Rect(round(x), round(y), round(x+w), round(y+h))
+	draw.Draw(p.img, r, image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func (p *pngImage) wedge(cx, cy, r, from, to float64, c color.RGBA) {
+	z := vector.NewRasterizer(width, height)
+	z.MoveTo(float32(cx), float32(cy))
+	steps := int(math.Ceil((to - from) / wedgeStep))
+	if steps < 1 {
+		steps = 1
+	}
+	for i := 0; i <= steps; i++ {
+		a := from + (to-from)*float64(i)/float64(steps)
+		z.LineTo(float32(cx+r*math.Sin(a)), float32(cy-r*math.Cos(a)))
+	}
+	z.ClosePath()
+	z.Draw(p.img, p.img.Bounds(), image.NewUniform(c), image.Point{})
+}
+
+func (p *pngImage) text(x, y float64, s string, a anchor, bold bool, c color.RGBA) {
+	d := font.Drawer{Dst: p.img, Src: image.NewUniform(c), Face: basicfont.Face7x13}
+	start := fixed.I(round(x))
+	switch a {
+	case anchorMiddle:
+		start -= d.MeasureString(s) / 2
+	case anchorEnd:
+		start -= d.MeasureString(s)
+	}
+	d.Dot = fixed.Point26_6{X: start, Y: fixed.I(round(y))}
+	d.DrawString(s)
+	if bold {
+		// The font has no bold face: the text is drawn again a pixel
+		// to the right instead.
+		d.Dot = fixed.Point26_6{X: start + fixed.I(1), Y: fixed.I(round(y))}
+		d.DrawString(s)
+	}
+}
+
+func round(v float64) int {
+	return int(math.Round(v))
+}
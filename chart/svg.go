@@ -0,0 +1,65 @@
+package chart
+
+import (
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"math"
+	"strings"
+)
+
+// svg is a canvas writing the elements of an SVG document.
+type svg struct {
+	doc strings.Builder
+}
+
+func newSVG() *svg {
+	s := &svg{}
+	fmt.Fprintf(&s.doc, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif" font-size="13">`+"\n",
+		width, height, width, height)
+	return s
+}
+
+func (s *svg) write(w io.Writer) error {
+	s.doc.WriteString("</svg>\n")
+	_, err := io.WriteString(w, s.doc.String())
+	return err
+}
+
+func (s *svg) rect(x, y, w, h float64, c color.RGBA) {
+	fmt.Fprintf(&s.doc, `  <rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`+"\n", x, y, w, h, hex(c))
+}
+
+func (s *svg) wedge(cx, cy, r, from, to float64, c color.RGBA) {
+	// An arc can't start and end at the same point, so a whole disc is
+	// drawn as a circle.
+	if to-from >= 2*math.Pi-1e-9 {
+		fmt.Fprintf(&s.doc, `  <circle cx="%.2f" cy="%.2f" r="%.2f" fill="%s"/>`+"\n", cx, cy, r, hex(c))
+		return
+	}
+	large := 0
+	if to-from > math.Pi {
+		large = 1
+	}
+	fmt.Fprintf(&s.doc, `  <path d="M %.2f %.2f L %.2f %.2f A %.2f %.2f 0 %d 1 %.2f %.2f Z" fill="%s"/>`+"\n",
+		cx, cy, cx+r*math.Sin(from), cy-r*math.Cos(from), r, r, large, cx+r*math.Sin(to), cy-r*math.Cos(to), hex(c))
+}
+
+func (s *svg) text(x, y float64, text string, a anchor, bold bool, c color.RGBA) {
+	attrs := ""
+	switch a {
+	case anchorMiddle:
+		attrs += ` text-anchor="middle"`
+	case anchorEnd:
+		attrs += ` text-anchor="end"`
+	}
+	if bold {
+		attrs += ` font-weight="bold"`
+	}
+	fmt.Fprintf(&s.doc, `  <text x="%.2f" y="%.2f" fill="%s"%s>%s</text>`+"\n", x, y, hex(c), attrs, html.EscapeString(text))
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/config"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/metrics"
+)
+
+const usage = `Usage:
+  ghinfo                            start the interactive prompts
+  ghinfo report [<report>] [flags]  run a report, where <report> is one of: %s,
+                                    or the report of the profile
+  ghinfo resolve [flags]            find the repository ID range for a creation date window
+  ghinfo snapshots [flags]          list the saved report snapshots
+  ghinfo diff [flags] <a> <b>       compare two report snapshots
+  ghinfo render <snapshot> [flags]  print the report of a snapshot again, e.g. sorted differently
+  ghinfo serve [flags]              serve the reports as JSON endpoints
+  ghinfo daemon --config <file>     run reports on schedules
+
+Run a command with -h to list its flags.
+`
+
+const dateLayout = "2006-01-02"
+
+func runCommand(args []string) error {
+	switch args[0] {
+	case "report":
+		return reportCommand(args[1:])
+	case "resolve":
+		return resolveCommand(args[1:])
+	case "snapshots":
+		return snapshotsCommand(args[1:])
+	case "diff":
+		return diffCommand(args[1:])
+	case "render":
+		return renderCommand(args[1:])
+	case "serve":
+		return serveCommand(args[1:])
+	case "daemon":
+		return daemonCommand(args[1:])
+	case "help", "-h", "--help":
+		fmt.Printf(usage, reportNames())
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n"+usage, args[0], reportNames())
+	}
+}
+
+func reportCommand(args []string) error {
+	// The report can be left to the profile.
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	var ids idRangeFlags
+	ids.register(fs)
+	column := fs.String("sort", "", "column to order the report by")
+	order := fs.String("order", "asc", "order to sort by, asc or desc")
+	top := fs.Int("top", 0, "only print the first N rows once sorted, for the owners report")
+	outputFlag := fs.String("output", "table", outputUsage)
+	chart := fs.Bool("chart", false, chartUsage)
+	var sample analytics.SampleOptions
+	fs.IntVar(&sample.Samples, "sample", 0, "estimate the report from this many random samples of the ID range, instead of crawling all of it")
+	fs.IntVar(&sample.Window, "window", 100, "number of consecutive IDs in each sample")
+	fs.Float64Var(&sample.Confidence, "confidence", 0.95, "confidence level of the estimated intervals")
+	fs.Int64Var(&sample.Seed, "seed", 0, "seed for drawing the samples, random when 0")
+	concurrency := fs.Int("concurrency", analytics.DefaultConcurrency, "number of repositories to retrieve the stars and license of at the same time")
+	save := fs.Bool("snapshot", false, "save a snapshot of the report results, to compare or render it later")
+	metricsFile := fs.String("metrics-file", "", "write the Prometheus metrics of the run to this file, e.g. for the node_exporter textfile collector")
+	store := registerStore(fs)
+	var client cassetteFlags
+	client.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	profile, err := client.api.loadProfile()
+	if err != nil {
+		return err
+	}
+	if err := applyProfile(fs, reportSettings(profile)); err != nil {
+		return err
+	}
+	if name == "" {
+		name = profile.Report
+	}
+	if name == "" {
+		return fmt.Errorf("please name the report to run, one of: %s", reportNames())
+	}
+	reportType, ok := analytics.ReportTypes[name]
+	if !ok {
+		return fmt.Errorf("unknown report %q, please use one of: %s", name, reportNames())
+	}
+
+	if *order != "asc" && *order != "desc" {
+		return fmt.Errorf("unknown order %q, please use asc or desc", *order)
+	}
+	if *concurrency < 1 {
+		return fmt.Errorf("the concurrency must be at least 1, got %d", *concurrency)
+	}
+	out, err := parseOutput(*outputFlag)
+	if err != nil {
+		return err
+	}
+	if *chart && sample.Samples > 0 {
+		return errors.New("sampled reports can't be charted, as they don't keep the repositories")
+	}
+	if *save && sample.Samples > 0 {
+		return errors.New("sampled reports can't be saved as snapshots, as they don't keep the repositories")
+	}
+	if *chart && out.format != "table" {
+		return fmt.Errorf("charts can only be printed below a table, not with --output %s", *outputFlag)
+	}
+
+	ctx := context.Background()
+	gh, err := client.newGithub(ctx, profile)
+	if err != nil {
+		return fmt.Errorf("error trying to initalize the GitHub client: %w", err)
+	}
+	collector := metrics.New()
+	gh.SetObserver(collector)
+
+	// Sampled reports draw their windows from ranges of any length.
+	maxIDs := analytics.MaxNumIDs
+	if sample.Samples > 0 {
+		maxIDs = 0
+	}
+	query, err := ids.query(ctx, gh, maxIDs)
+	if errors.Is(err, github.ErrRangeTooLong) {
+		return fmt.Errorf("%w, the most a report crawls: please use a shorter window, or --sample to estimate over it", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	opts := analytics.ParamOptions{
+		Column:      *column,
+		Asc:         *order == "asc",
+		Top:         *top,
+		Since:       query.Since,
+		MaxID:       query.MaxID,
+		Concurrency: *concurrency,
+		Chart:       *chart,
+	}
+
+	var report analytics.StatsReport
+	if sample.Samples > 0 {
+		if sample.Seed == 0 {
+			sample.Seed = time.Now().UnixNano()
+		}
+		fmt.Fprintf(os.Stderr, "Sampling with seed %d, use --seed to draw the same samples again.\n\n", sample.Seed)
+		report, err = analytics.NewSampleReport(reportType, opts, sample)
+	} else {
+		report, err = analytics.NewReport(reportType, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid options were selected: %w", err)
+	}
+	if err := out.check(report); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "We are about to retrive data for your %s ...\n\n", report.Name())
+	err = report.Run(ctx, gh)
+	if *metricsFile != "" {
+		collector.RecordRun(report, err, time.Now())
+		if err := collector.WriteFile(*metricsFile); err != nil {
+			return fmt.Errorf("error trying to write the metrics: %w", err)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error trying to retrieve the repository list: %w", err)
+	}
+
+	api := collector.API()
+	header := analytics.Header{Since: query.Since, MaxID: query.MaxID, At: time.Now(), API: &api}
+	if err := out.write(ctx, report, header); err != nil {
+		return err
+	}
+	if *save {
+		return saveSnapshot(report, *store)
+	}
+	return nil
+}
+
+func resolveCommand(args []string) error {
+	fs := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	var ids idRangeFlags
+	ids.registerDates(fs)
+	var client cassetteFlags
+	client.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if ids.createdFrom == "" || ids.createdTo == "" {
+		return errors.New("both --created-from and --created-to are required")
+	}
+
+	profile, err := client.api.loadProfile()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	gh, err := client.newGithub(ctx, profile)
+	if err != nil {
+		return fmt.Errorf("error trying to initalize the GitHub client: %w", err)
+	}
+
+	query, err := ids.query(ctx, gh, 0)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Repositories created between %s and %s: --since %d --max-id %d\n",
+		ids.createdFrom, ids.createdTo, query.Since, query.MaxID)
+	return nil
+}
+
+// idRangeFlags selects the repositories for a report, either by ID
+// or by creation date.
+type idRangeFlags struct {
+	since, maxID           int
+	createdFrom, createdTo string
+}
+
+func (f *idRangeFlags) register(fs *flag.FlagSet) {
+	fs.IntVar(&f.since, "since", config.Defaults.Since, "only include repositories with an ID greater than this one")
+	fs.IntVar(&f.maxID, "max-id", config.Defaults.MaxID, "only include repositories with an ID up to this one")
+	f.registerDates(fs)
+}
+
+func (f *idRangeFlags) registerDates(fs *flag.FlagSet) {
+	fs.StringVar(&f.createdFrom, "created-from", "",
+		"only include repositories created on or after this date ("+dateLayout+" or RFC 3339), instead of --since")
+	fs.StringVar(&f.createdTo, "created-to", "",
+		"only include repositories created on or before this date ("+dateLayout+" or RFC 3339), instead of --max-id")
+}
+
+// query returns the ID range to query, resolving the creation dates
+// to IDs when they were given. The resolution fails early with
+// github.ErrRangeTooLong when the window spans more than maxIDs IDs,
+// unless maxIDs is 0.
+func (f *idRangeFlags) query(ctx context.Context, gh *github.Github, maxIDs int) (github.Query, error) {
+	if f.createdFrom == "" && f.createdTo == "" {
+		return github.Query{Since: f.since, MaxID: f.maxID}, nil
+	}
+	if f.createdFrom == "" || f.createdTo == "" {
+		return github.Query{}, errors.New("--created-from and --created-to must be used together")
+	}
+
+	from, err := parseDate(f.createdFrom, false)
+	if err != nil {
+		return github.Query{}, err
+	}
+	to, err := parseDate(f.createdTo, true)
+	if err != nil {
+		return github.Query{}, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Looking up the repository IDs for repositories created between %s and %s...\n",
+		f.createdFrom, f.createdTo)
+	if maxIDs > 0 {
+		fmt.Fprintf(os.Stderr, "A report crawls at most %d IDs, a few minutes of repository creations: use --sample to estimate over longer windows.\n", maxIDs)
+	}
+	fmt.Fprintln(os.Stderr)
+	return gh.ResolveDateRangeWithin(ctx, from, to, maxIDs)
+}
+
+// parseDate parses either a date or an RFC 3339 timestamp. Dates are read
+// as the start of the day, or as its end when endOfDay is set.
+func parseDate(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(dateLayout, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date (%s) or an RFC 3339 timestamp", v, dateLayout)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+func reportNames() string {
+	var names []string
+	for name := range analytics.ReportTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
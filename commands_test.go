@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github/githubtest"
+)
+
+// setenv sets the environment variable for the rest of the test.
+func setenv(t *testing.T, key, value string) {
+	prev, ok := os.LookupEnv(key)
+	os.Setenv(key, value)
+	t.Cleanup(func() {
+		if ok {
+			os.Setenv(key, prev)
+		} else {
+			os.Unsetenv(key)
+		}
+	})
+}
+
+// captureStdout returns what f writes to stdout.
+func captureStdout(t *testing.T, f func() error) string {
+	out, err := ioutil.TempFile(t.TempDir(), "stdout")
+	require.NoError(t, err)
+	defer out.Close()
+
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+	require.NoError(t, f())
+
+	b, err := ioutil.ReadFile(out.Name())
+	require.NoError(t, err)
+	return string(b)
+}
+
+// TestReportCommandStdout asserts that only the report is written to
+// stdout, the progress going to stderr, so that it can be redirected to a
+// file.
+func TestReportCommandStdout(t *testing.T) {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 1, Count: 30, FirstID: 1000}))
+	server.PathPrefix = "/api/v3"
+	defer server.Close()
+	repos := server.Repos()
+
+	setenv(t, "GH_TOKEN", "token")
+	setenv(t, "XDG_CONFIG_HOME", t.TempDir())
+	setenv(t, "XDG_DATA_HOME", t.TempDir())
+	args := func(output string) []string {
+		return []string{"licenses", "--since", "999", "--max-id", strconv.Itoa(repos[len(repos)-1].ID),
+			"--base-url", server.URL + server.PathPrefix, "--output", output}
+	}
+
+	out := captureStdout(t, func() error { return reportCommand(args("json")) })
+	var report map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &report), out)
+	require.NotEmpty(t, report)
+
+	out = captureStdout(t, func() error { return reportCommand(args("markdown")) })
+	require.True(t, strings.HasPrefix(out, "## License Types Report\n"), out)
+
+	out = captureStdout(t, func() error { return reportCommand(args("svg")) })
+	require.True(t, strings.HasPrefix(out, "<svg "), out)
+	require.True(t, strings.HasSuffix(out, "</svg>\n"), out)
+	require.EqualError(t, reportCommand(args("png")), "please give the image file to write to, e.g. --output png=charts.png")
+}
@@ -0,0 +1,292 @@
+// Package config reads the ghinfo configuration file, which holds the
+// settings shared by every command, and named profiles overriding them:
+//
+//	base_url: https://github.example.com
+//	ca_bundle: /etc/ssl/certs/example-ca.pem
+//	default_profile: nightly
+//	profiles:
+//	  nightly:
+//	    report: licenses
+//	    since: 65624570
+//	    max_id: 65624720
+//	    concurrency: 8
+//	    output: csv=licenses.csv
+//	  public:
+//	    base_url: https://api.github.com
+//	    token_source: gh
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is the name of the profile used when none is selected.
+const DefaultProfile = "default"
+
+// Defaults are the settings used when neither the configuration file
+// nor the profile set them.
+var Defaults = Profile{
+	Since: 65624570,
+	MaxID: 65624720,
+}
+
+// Config is the content of the configuration file.
+type Config struct {
+	// Profile holds the settings of every profile, unless the profile
+	// sets them too.
+	Profile `yaml:",inline"`
+	// DefaultProfile is the profile used when none is selected,
+	// DefaultProfile when empty.
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]Profile `yaml:"profiles"`
+}
+
+// Profile is a set of settings. A setting is unset when the configuration
+// file doesn't hold it, so that e.g. `since: 0` in a profile overrides the
+// shared since, or, for profiles not read from a file, when it is the zero
+// value.
+type Profile struct {
+	// BaseURL is the REST API URL, or the URL of a GitHub Enterprise
+	// Server, https://api.github.com when empty.
+	BaseURL string `yaml:"base_url"`
+	// GraphQLURL is the GraphQL API URL, derived from BaseURL when
+	// empty.
+	GraphQLURL string `yaml:"graphql_url"`
+	// CABundle is a PEM file of certificate authorities trusted along
+	// with the system ones, e.g. for a GitHub Enterprise Server with a
+	// certificate signed by an internal authority.
+	CABundle string `yaml:"ca_bundle"`
+	// TokenSource is where the token is taken from, one of TokenSources.
+	TokenSource string `yaml:"token_source"`
+
+	// Report is the report run when none is named.
+	Report      string `yaml:"report"`
+	Since       int    `yaml:"since"`
+	MaxID       int    `yaml:"max_id"`
+	Sort        string `yaml:"sort"`
+	Order       string `yaml:"order"`
+	Concurrency int    `yaml:"concurrency"`
+	// Snapshot saves a snapshot of each report run, in Store.
+	Snapshot bool `yaml:"snapshot"`
+	// Store is the directory the snapshots are saved in.
+	Store string `yaml:"store"`
+	// Output is the format of the reports, as given to --output.
+	Output string `yaml:"output"`
+
+	// set are the settings held by the configuration file, by key.
+	set map[string]bool
+}
+
+// TokenSources are the accepted token sources: `auto` looks in every
+// place in turn, `env:NAME` reads the NAME environment variable, `gh`,
+// `netrc` and `git` read the credentials of these tools, `app` and
+// `tokens` authenticate as a GitHub App or with a pool of tokens, and
+// `none` calls the API unauthenticated.
+var TokenSources = []string{"auto", "env:NAME", "gh", "netrc", "git", "app", "tokens", "none"}
+
+// DefaultPath returns the path of the configuration file.
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "ghinfo", "config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "ghinfo", "config.yaml"), nil
+}
+
+// Load reads the configuration file at path.
+func Load(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	// An empty file is a valid, empty, configuration.
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("error trying to read %s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration in %s: %w", path, err)
+	}
+	if err := c.readKeys(data); err != nil {
+		return Config{}, fmt.Errorf("error trying to read %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// readKeys records the settings held by the configuration file, shared
+// and in each profile, as decoding it leaves the missing ones and the
+// ones set to their zero value alike.
+func (c *Config) readKeys(data []byte) error {
+	var keys struct {
+		Shared   map[string]interface{}            `yaml:",inline"`
+		Profiles map[string]map[string]interface{} `yaml:"profiles"`
+	}
+	if err := yaml.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	present := func(settings map[string]interface{}) map[string]bool {
+		set := make(map[string]bool)
+		for key, v := range settings {
+			// `since:` without a value leaves it unset.
+			if v != nil {
+				set[key] = true
+			}
+		}
+		return set
+	}
+	c.Profile.set = present(keys.Shared)
+	for name, p := range c.Profiles {
+		p.set = present(keys.Profiles[name])
+		c.Profiles[name] = p
+	}
+	return nil
+}
+
+// LoadDefault reads the configuration file at DefaultPath, if there is
+// one.
+func LoadDefault() (Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return Config{}, err
+	}
+	c, err := Load(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	return c, err
+}
+
+func (c Config) validate() error {
+	if err := c.Profile.validate(); err != nil {
+		return err
+	}
+	for name, p := range c.Profiles {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+	}
+	if c.DefaultProfile != "" && c.DefaultProfile != DefaultProfile {
+		if _, ok := c.Profiles[c.DefaultProfile]; !ok {
+			return fmt.Errorf("the default profile %q is not defined", c.DefaultProfile)
+		}
+	}
+	return nil
+}
+
+func (p Profile) validate() error {
+	if !validTokenSource(p.TokenSource) {
+		return fmt.Errorf("unknown token source %q, please use one of: %s", p.TokenSource, strings.Join(TokenSources, ", "))
+	}
+	switch p.Order {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("unknown order %q, please use asc or desc", p.Order)
+	}
+	if p.Since < 0 || p.MaxID < 0 || p.Concurrency < 0 {
+		return errors.New("since, max_id and concurrency can't be negative")
+	}
+	return nil
+}
+
+func validTokenSource(s string) bool {
+	if s == "" || strings.HasPrefix(s, "env:") && len(s) > len("env:") {
+		return true
+	}
+	for _, source := range TokenSources {
+		if s == source && source != "env:NAME" {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the settings of the named profile: the settings of the
+// profile, then the shared ones, then the Defaults. When name is empty,
+// the default profile is selected. The DefaultProfile profile doesn't
+// need to be defined, unlike the other ones.
+func (c Config) Select(name string) (Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok && name != DefaultProfile {
+		return Profile{}, fmt.Errorf("unknown profile %q, please use one of: %s", name, strings.Join(c.names(), ", "))
+	}
+	p = p.merge(c.Profile).merge(Defaults)
+	p.set = nil
+	return p, nil
+}
+
+// names returns the names of the profiles, sorted.
+func (c Config) names() []string {
+	names := []string{DefaultProfile}
+	for name := range c.Profiles {
+		if name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// merge returns p, with the settings it leaves unset taken from base.
+// The settings of either are set in the result.
+func (p Profile) merge(base Profile) Profile {
+	str := func(key string, v *string, b string) {
+		if *v == "" && !p.set[key] {
+			*v = b
+		}
+	}
+	num := func(key string, v *int, b int) {
+		if *v == 0 && !p.set[key] {
+			*v = b
+		}
+	}
+
+	str("base_url", &p.BaseURL, base.BaseURL)
+	str("graphql_url", &p.GraphQLURL, base.GraphQLURL)
+	str("ca_bundle", &p.CABundle, base.CABundle)
+	str("token_source", &p.TokenSource, base.TokenSource)
+	str("report", &p.Report, base.Report)
+	num("since", &p.Since, base.Since)
+	num("max_id", &p.MaxID, base.MaxID)
+	str("sort", &p.Sort, base.Sort)
+	str("order", &p.Order, base.Order)
+	num("concurrency", &p.Concurrency, base.Concurrency)
+	if !p.Snapshot && !p.set["snapshot"] {
+		p.Snapshot = base.Snapshot
+	}
+	str("store", &p.Store, base.Store)
+	str("output", &p.Output, base.Output)
+
+	set := make(map[string]bool, len(p.set)+len(base.set))
+	for key := range base.set {
+		set[key] = true
+	}
+	for key := range p.set {
+		set[key] = true
+	}
+	p.set = set
+	return p
+}
@@ -0,0 +1,193 @@
+package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/config"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			config: `
+base_url: https://github.example.com
+default_profile: nightly
+profiles:
+  nightly:
+    report: licenses
+    token_source: env:NIGHTLY_TOKEN
+    order: desc
+  public:
+    base_url: https://api.github.com
+    token_source: gh
+`,
+		},
+		{
+			name:   "empty",
+			config: "",
+		},
+		{
+			name:          "unknown field",
+			config:        "base_uri: https://github.example.com\n",
+			expectedError: "field base_uri not found",
+		},
+		{
+			name:          "unknown default profile",
+			config:        "default_profile: nightly\n",
+			expectedError: `the default profile "nightly" is not defined`,
+		},
+		{
+			name:          "unknown token source",
+			config:        "profiles:\n  ci:\n    token_source: keyring\n",
+			expectedError: `profile "ci": unknown token source "keyring"`,
+		},
+		{
+			name:          "env token source without a name",
+			config:        "token_source: 'env:'\n",
+			expectedError: `unknown token source "env:"`,
+		},
+		{
+			name:          "unknown order",
+			config:        "order: up\n",
+			expectedError: `unknown order "up"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := config.Load(writeConfig(t, tc.config))
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedError)
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	c, err := config.Load(writeConfig(t, `
+base_url: https://github.example.com
+concurrency: 2
+default_profile: nightly
+profiles:
+  nightly:
+    report: licenses
+    since: 100
+    max_id: 200
+    concurrency: 8
+  public:
+    base_url: https://api.github.com
+    graphql_url: https://api.github.com/graphql
+    token_source: gh
+    output: json
+`))
+	require.NoError(t, err)
+
+	nightly, err := c.Select("")
+	require.NoError(t, err)
+	require.Equal(t, config.Profile{
+		BaseURL:     "https://github.example.com",
+		Report:      "licenses",
+		Since:       100,
+		MaxID:       200,
+		Concurrency: 8,
+	}, nightly)
+
+	public, err := c.Select("public")
+	require.NoError(t, err)
+	require.Equal(t, config.Profile{
+		BaseURL:     "https://api.github.com",
+		GraphQLURL:  "https://api.github.com/graphql",
+		TokenSource: "gh",
+		Since:       config.Defaults.Since,
+		MaxID:       config.Defaults.MaxID,
+		Concurrency: 2,
+		Output:      "json",
+	}, public)
+
+	// The default profile doesn't need to be defined.
+	def, err := c.Select(config.DefaultProfile)
+	require.NoError(t, err)
+	require.Equal(t, "https://github.example.com", def.BaseURL)
+	require.Equal(t, config.Defaults.Since, def.Since)
+
+	_, err = c.Select("weekly")
+	require.EqualError(t, err, `unknown profile "weekly", please use one of: default, nightly, public`)
+
+	def, err = config.Config{}.Select("")
+	require.NoError(t, err)
+	require.Equal(t, config.Defaults, def)
+}
+
+// TestSelectZero asserts that the settings a profile sets to their zero
+// value override the shared ones and the Defaults.
+func TestSelectZero(t *testing.T) {
+	c, err := config.Load(writeConfig(t, `
+since: 100
+concurrency: 8
+snapshot: true
+output: json
+profiles:
+  full:
+    since: 0
+    concurrency: 0
+    snapshot: false
+    output: ""
+  unset:
+    since:
+  shared:
+`))
+	require.NoError(t, err)
+
+	full, err := c.Select("full")
+	require.NoError(t, err)
+	require.Equal(t, config.Profile{MaxID: config.Defaults.MaxID}, full)
+
+	unset, err := c.Select("unset")
+	require.NoError(t, err)
+	require.Equal(t, config.Profile{Since: 100, MaxID: config.Defaults.MaxID, Concurrency: 8, Snapshot: true, Output: "json"}, unset)
+
+	shared, err := c.Select("shared")
+	require.NoError(t, err)
+	require.Equal(t, unset, shared)
+
+	c, err = config.Load(writeConfig(t, "since: 0\n"))
+	require.NoError(t, err)
+	def, err := c.Select("")
+	require.NoError(t, err)
+	require.Equal(t, 0, def.Since)
+}
+
+func TestLoadDefault(t *testing.T) {
+	dir := t.TempDir()
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	os.Setenv("XDG_CONFIG_HOME", dir)
+
+	c, err := config.LoadDefault()
+	require.NoError(t, err)
+	require.Equal(t, config.Config{}, c)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "ghinfo"), 0o755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "ghinfo", "config.yaml"), []byte("ca_bundle: /etc/ssl/example-ca.pem\n"), 0o644))
+	c, err = config.LoadDefault()
+	require.NoError(t, err)
+	require.Equal(t, "/etc/ssl/example-ca.pem", c.CABundle)
+}
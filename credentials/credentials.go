@@ -0,0 +1,236 @@
+// Package credentials finds a token for the GitHub API among the places
+// developers usually keep one: the environment, the gh CLI configuration,
+// the .netrc file and the git credential helpers.
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotFound is returned when no token is found.
+var ErrNotFound = errors.New("no GitHub token found")
+
+// gitTimeout bounds `git credential fill`, in case a helper hangs.
+const gitTimeout = 5 * time.Second
+
+// Token is a token found, along with where it was found.
+type Token struct {
+	Value string
+	// Source describes where the token was found, e.g. `the GH_TOKEN
+	// environment variable`.
+	Source string
+}
+
+// Finder looks for tokens. The zero value looks in the real environment
+// and home directory.
+type Finder struct {
+	// Getenv is os.Getenv when nil.
+	Getenv func(string) string
+	// HomeDir is the home directory of the user when empty.
+	HomeDir string
+	// GitCredential runs `git credential fill` with the given input,
+	// and returns its output. It runs git when nil.
+	GitCredential func(ctx context.Context, input string) (string, error)
+}
+
+// Find returns the first token found for host, e.g. github.com, in order:
+// the GH_TOKEN and GITHUB_TOKEN environment variables, the hosts file of
+// the gh CLI, the .netrc file, and `git credential fill`.
+func (f Finder) Find(ctx context.Context, host string) (Token, error) {
+	for _, source := range []string{"env:GH_TOKEN", "env:GITHUB_TOKEN", "gh", "netrc", "git"} {
+		token, err := f.FindIn(ctx, host, source)
+		if !errors.Is(err, ErrNotFound) {
+			return token, err
+		}
+	}
+	return Token{}, ErrNotFound
+}
+
+// FindIn returns the token found for host in a single source: `env:NAME`
+// for the NAME environment variable, `gh`, `netrc` or `git`.
+func (f Finder) FindIn(ctx context.Context, host, source string) (Token, error) {
+	var lookup func(context.Context, string) (Token, bool, error)
+	switch source {
+	case "gh":
+		lookup = f.fromGhHosts
+	case "netrc":
+		lookup = f.fromNetrc
+	case "git":
+		lookup = f.fromGit
+	default:
+		name := strings.TrimPrefix(source, "env:")
+		if name == source || name == "" {
+			return Token{}, fmt.Errorf("unknown token source %q", source)
+		}
+		lookup = func(context.Context, string) (Token, bool, error) {
+			v := f.getenv(name)
+			return Token{Value: v, Source: "the " + name + " environment variable"}, v != "", nil
+		}
+	}
+
+	token, ok, err := lookup(ctx, host)
+	if err != nil {
+		return Token{}, err
+	}
+	if !ok {
+		return Token{}, fmt.Errorf("%w in %s", ErrNotFound, source)
+	}
+	return token, nil
+}
+
+func (f Finder) getenv(name string) string {
+	if f.Getenv == nil {
+		return os.Getenv(name)
+	}
+	return f.Getenv(name)
+}
+
+func (f Finder) homeDir() string {
+	if f.HomeDir != "" {
+		return f.HomeDir
+	}
+	home, _ := os.UserHomeDir()
+	return home
+}
+
+// fromGhHosts reads the token the gh CLI was logged in with. Recent
+// versions of gh keep it in the system keyring instead, where it isn't
+// looked for.
+func (f Finder) fromGhHosts(_ context.Context, host string) (Token, bool, error) {
+	dir := f.getenv("GH_CONFIG_DIR")
+	switch {
+	case dir != "":
+	case f.getenv("XDG_CONFIG_HOME") != "":
+		dir = filepath.Join(f.getenv("XDG_CONFIG_HOME"), "gh")
+	default:
+		dir = filepath.Join(f.homeDir(), ".config", "gh")
+	}
+	path := filepath.Join(dir, "hosts.yml")
+
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Token{}, false, nil
+	}
+	if err != nil {
+		return Token{}, false, err
+	}
+
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		// Not ours to fix, and the other sources may have a token.
+		return Token{}, false, nil
+	}
+	if token := hosts[host].OAuthToken; token != "" {
+		return Token{Value: token, Source: "the gh CLI configuration " + path}, true, nil
+	}
+	return Token{}, false, nil
+}
+
+// fromNetrc reads the password of the host, or of its API host, in the
+// .netrc file.
+func (f Finder) fromNetrc(_ context.Context, host string) (Token, bool, error) {
+	path := f.getenv("NETRC")
+	if path == "" {
+		path = filepath.Join(f.homeDir(), ".netrc")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Token{}, false, nil
+	}
+	if err != nil {
+		return Token{}, false, err
+	}
+
+	passwords := parseNetrc(data)
+	for _, machine := range []string{host, "api." + host} {
+		if password := passwords[machine]; password != "" {
+			return Token{Value: password, Source: "the " + machine + " machine of " + path}, true, nil
+		}
+	}
+	return Token{}, false, nil
+}
+
+// parseNetrc returns the passwords of the file by machine. Macros are not
+// supported, and the default entry is left out, as its password is
+// unlikely to be a GitHub token.
+func parseNetrc(data []byte) map[string]string {
+	passwords := make(map[string]string)
+	fields := strings.Fields(string(data))
+	machine := ""
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				machine = fields[i]
+			}
+		case "default":
+			machine = ""
+		case "login", "account":
+			i++
+		case "password":
+			if i+1 < len(fields) {
+				i++
+				if machine != "" {
+					passwords[machine] = fields[i]
+				}
+			}
+		}
+	}
+	return passwords
+}
+
+// fromGit asks the git credential helpers for the password of the host,
+// without ever prompting for one.
+func (f Finder) fromGit(ctx context.Context, host string) (Token, bool, error) {
+	run := f.GitCredential
+	if run == nil {
+		run = gitCredentialFill
+	}
+
+	out, err := run(ctx, "protocol=https\nhost="+host+"\n\n")
+	if err != nil {
+		// No git, or no helper with a credential for the host.
+		return Token{}, false, nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if password := strings.TrimPrefix(scanner.Text(), "password="); password != scanner.Text() && password != "" {
+			return Token{Value: password, Source: "the git credential helper"}, true, nil
+		}
+	}
+	return Token{}, false, nil
+}
+
+func gitCredentialFill(ctx context.Context, input string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(input)
+	// Fails instead of prompting when no helper has a credential, the Git
+	// Credential Manager opening its own sign in window otherwise.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,98 @@
+package credentials_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/credentials"
+)
+
+func TestFinder(t *testing.T) {
+	home := t.TempDir()
+	env := map[string]string{}
+	gitOutput := ""
+	f := credentials.Finder{
+		Getenv:  func(name string) string { return env[name] },
+		HomeDir: home,
+		GitCredential: func(_ context.Context, input string) (string, error) {
+			require.Equal(t, "protocol=https\nhost=github.com\n\n", input)
+			if gitOutput == "" {
+				return "", errors.New("exit status 128")
+			}
+			return gitOutput, nil
+		},
+	}
+	find := func() credentials.Token {
+		token, err := f.Find(context.Background(), "github.com")
+		require.NoError(t, err)
+		return token
+	}
+
+	_, err := f.Find(context.Background(), "github.com")
+	require.True(t, errors.Is(err, credentials.ErrNotFound))
+
+	gitOutput = "protocol=https\nhost=github.com\nusername=octocat\npassword=from-git\n"
+	require.Equal(t, credentials.Token{Value: "from-git", Source: "the git credential helper"}, find())
+
+	netrc := filepath.Join(home, ".netrc")
+	require.NoError(t, ioutil.WriteFile(netrc, []byte("machine example.com login me password nope\n"+
+		"machine api.github.com\n  login octocat\n  password from-netrc\ndefault login anonymous password guest\n"), 0o600))
+	require.Equal(t, credentials.Token{Value: "from-netrc", Source: "the api.github.com machine of " + netrc}, find())
+
+	gh := filepath.Join(home, ".config", "gh")
+	require.NoError(t, os.MkdirAll(gh, 0o755))
+	hosts := filepath.Join(gh, "hosts.yml")
+	require.NoError(t, ioutil.WriteFile(hosts, []byte("github.com:\n    user: octocat\n    oauth_token: from-gh\n    git_protocol: https\n"), 0o600))
+	require.Equal(t, credentials.Token{Value: "from-gh", Source: "the gh CLI configuration " + hosts}, find())
+
+	env["GITHUB_TOKEN"] = "from-github-token"
+	require.Equal(t, credentials.Token{Value: "from-github-token", Source: "the GITHUB_TOKEN environment variable"}, find())
+
+	env["GH_TOKEN"] = "from-gh-token"
+	require.Equal(t, credentials.Token{Value: "from-gh-token", Source: "the GH_TOKEN environment variable"}, find())
+}
+
+func TestFinderOtherHosts(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(home, ".netrc"), []byte("machine github.com login me password web\n"), 0o600))
+	f := credentials.Finder{
+		Getenv:  func(string) string { return "" },
+		HomeDir: home,
+		GitCredential: func(context.Context, string) (string, error) {
+			return "protocol=https\nhost=github.example.com\n", nil
+		},
+	}
+
+	token, err := f.Find(context.Background(), "github.com")
+	require.NoError(t, err)
+	require.Equal(t, "web", token.Value)
+
+	// Neither the netrc entry of another host, nor a git answer
+	// without a password, are used.
+	_, err = f.Find(context.Background(), "github.example.com")
+	require.True(t, errors.Is(err, credentials.ErrNotFound))
+}
+
+func TestFinderFindIn(t *testing.T) {
+	f := credentials.Finder{
+		Getenv:  func(name string) string { return map[string]string{"CI_TOKEN": "from-ci"}[name] },
+		HomeDir: t.TempDir(),
+	}
+
+	token, err := f.FindIn(context.Background(), "github.com", "env:CI_TOKEN")
+	require.NoError(t, err)
+	require.Equal(t, credentials.Token{Value: "from-ci", Source: "the CI_TOKEN environment variable"}, token)
+
+	_, err = f.FindIn(context.Background(), "github.com", "netrc")
+	require.True(t, errors.Is(err, credentials.ErrNotFound))
+	require.EqualError(t, err, "no GitHub token found in netrc")
+
+	_, err = f.FindIn(context.Background(), "github.com", "keyring")
+	require.EqualError(t, err, `unknown token source "keyring"`)
+}
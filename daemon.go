@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/carlisia/ghinfo/daemon"
+)
+
+func daemonCommand(args []string) error {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	configPath := fs.String("config", "", "YAML file listing the reports to run and their schedules")
+	var api apiFlags
+	api.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *configPath == "" {
+		return errors.New("please give the file listing the scheduled reports with --config")
+	}
+
+	config, err := daemon.LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(config.Store)
+	if err != nil {
+		return err
+	}
+
+	profile, err := api.loadProfile()
+	if err != nil {
+		return err
+	}
+	settings, err := api.resolve(profile)
+	if err != nil {
+		return err
+	}
+
+	// Canceled on SIGINT or SIGTERM, which stops the running reports.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	gh, err := newGithub(ctx, settings)
+	if err != nil {
+		return fmt.Errorf("error trying to initalize the GitHub client: %w", err)
+	}
+
+	log.Printf("Running %d scheduled reports, saving the snapshots to %s", len(config.Jobs), store.Dir)
+	if err := daemon.New(gh, config, store).Run(ctx); err != nil {
+		return err
+	}
+	log.Println("Stopped")
+	return nil
+}
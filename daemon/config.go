@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/carlisia/ghinfo/analytics"
+)
+
+// Config lists the reports to run, and when. For example:
+//
+//	store: /var/lib/ghinfo/snapshots
+//	metrics_file: /var/lib/node_exporter/ghinfo.prom
+//	jobs:
+//	  - name: nightly-licenses
+//	    report: licenses
+//	    schedule: "0 3 * * *"
+//	    window: 500
+//	  - name: hourly-stars
+//	    report: stars
+//	    schedule: "@hourly"
+//	    since: 65624570
+//	    max_id: 65624720
+//	    sort: stars
+//	    order: desc
+type Config struct {
+	// Store is the directory the snapshots are saved in, the default
+	// snapshot directory when empty.
+	Store string `yaml:"store"`
+	// MetricsFile, when set, is written with the Prometheus metrics
+	// after each run.
+	MetricsFile string `yaml:"metrics_file"`
+	Jobs        []Job  `yaml:"jobs"`
+}
+
+// Job is a report run on a schedule.
+type Job struct {
+	Name     string `yaml:"name"`
+	Report   string `yaml:"report"`
+	Schedule string `yaml:"schedule"`
+	// Window, when set, runs the report over the latest `window` IDs
+	// at the time of each run, instead of the fixed Since and MaxID.
+	Window int    `yaml:"window"`
+	Since  int    `yaml:"since"`
+	MaxID  int    `yaml:"max_id"`
+	Sort   string `yaml:"sort"`
+	Order  string `yaml:"order"`
+	Top    int    `yaml:"top"`
+	// Concurrency is the number of repositories enriched at the same
+	// time, analytics.DefaultConcurrency when not set.
+	Concurrency int `yaml:"concurrency"`
+
+	schedule   Schedule
+	reportType string
+}
+
+// LoadConfig reads and validates the configuration file at path.
+func LoadConfig(path string) (Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var c Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return Config{}, fmt.Errorf("error trying to read %s: %w", path, err)
+	}
+	if err := c.validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration in %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Config) validate() error {
+	if len(c.Jobs) == 0 {
+		return errors.New("there are no jobs to run")
+	}
+
+	names := make(map[string]bool)
+	for i := range c.Jobs {
+		job := &c.Jobs[i]
+		if job.Name == "" {
+			return fmt.Errorf("job %d has no name", i+1)
+		}
+		if names[job.Name] {
+			return fmt.Errorf("there is more than one job named %q", job.Name)
+		}
+		names[job.Name] = true
+
+		if err := job.validate(); err != nil {
+			return fmt.Errorf("job %q: %w", job.Name, err)
+		}
+	}
+	return nil
+}
+
+func (j *Job) validate() error {
+	var ok bool
+	if j.reportType, ok = analytics.ReportTypes[j.Report]; !ok {
+		return fmt.Errorf("unknown report %q", j.Report)
+	}
+
+	var err error
+	if j.schedule, err = ParseSchedule(j.Schedule); err != nil {
+		return err
+	}
+
+	switch j.Order {
+	case "":
+		j.Order = "asc"
+	case "asc", "desc":
+	default:
+		return fmt.Errorf("unknown order %q, please use asc or desc", j.Order)
+	}
+
+	if j.Concurrency < 0 {
+		return fmt.Errorf("the concurrency must be a positive number, got %d", j.Concurrency)
+	}
+
+	if j.Window != 0 {
+		if j.Since != 0 || j.MaxID != 0 {
+			return errors.New("either set a window or since and max_id, not both")
+		}
+		if j.Window < 0 {
+			return fmt.Errorf("the window must be a positive number of IDs, got %d", j.Window)
+		}
+		// Checks the window size, and the other options, against a
+		// range of the same size.
+		_, err = analytics.NewReport(j.reportType, j.options(0, j.Window))
+		return err
+	}
+	if j.MaxID == 0 {
+		return errors.New("please set either a window or since and max_id")
+	}
+	_, err = analytics.NewReport(j.reportType, j.options(j.Since, j.MaxID))
+	return err
+}
+
+func (j *Job) options(since, maxID int) analytics.ParamOptions {
+	return analytics.ParamOptions{
+		Column:      j.Sort,
+		Asc:         j.Order == "asc",
+		Top:         j.Top,
+		Since:       since,
+		MaxID:       maxID,
+		Concurrency: j.Concurrency,
+	}
+}
@@ -0,0 +1,101 @@
+package daemon_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/daemon"
+)
+
+func TestLoadConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        string
+		expectedError string
+	}{
+		{
+			name: "valid",
+			config: `
+store: /tmp/snapshots
+jobs:
+  - name: nightly-licenses
+    report: licenses
+    schedule: "0 3 * * *"
+    window: 500
+  - name: hourly-stars
+    report: stars
+    schedule: "@hourly"
+    since: 65624570
+    max_id: 65624720
+    sort: stars
+    order: desc
+`,
+		},
+		{
+			name:          "no jobs",
+			config:        "store: /tmp/snapshots\n",
+			expectedError: "there are no jobs to run",
+		},
+		{
+			name:          "unknown field",
+			config:        "jobs:\n  - name: a\n    report: stars\n    schedule: '@daily'\n    windw: 10\n",
+			expectedError: "field windw not found",
+		},
+		{
+			name:          "duplicate names",
+			config:        "jobs:\n  - {name: a, report: stars, schedule: '@daily', window: 10}\n  - {name: a, report: stars, schedule: '@daily', window: 10}\n",
+			expectedError: `there is more than one job named "a"`,
+		},
+		{
+			name:          "unknown report",
+			config:        "jobs:\n  - {name: a, report: forks, schedule: '@daily', window: 10}\n",
+			expectedError: `job "a": unknown report "forks"`,
+		},
+		{
+			name:          "invalid schedule",
+			config:        "jobs:\n  - {name: a, report: stars, schedule: 'daily', window: 10}\n",
+			expectedError: `job "a": schedule "daily" must have 5 fields`,
+		},
+		{
+			name:          "window too long",
+			config:        "jobs:\n  - {name: a, report: stars, schedule: '@daily', window: 1000}\n",
+			expectedError: `job "a": the number of IDs (1000)  has exceeded the limit (500)`,
+		},
+		{
+			name:          "window and fixed range",
+			config:        "jobs:\n  - {name: a, report: stars, schedule: '@daily', window: 10, max_id: 20}\n",
+			expectedError: `job "a": either set a window or since and max_id, not both`,
+		},
+		{
+			name:          "no range",
+			config:        "jobs:\n  - {name: a, report: stars, schedule: '@daily'}\n",
+			expectedError: `job "a": please set either a window or since and max_id`,
+		},
+		{
+			name:          "unknown sort column",
+			config:        "jobs:\n  - {name: a, report: licenses, schedule: '@daily', window: 10, sort: stars}\n",
+			expectedError: `job "a": "stars" is not a column of this report`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "daemon.yaml")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tc.config), 0o644))
+
+			config, err := daemon.LoadConfig(path)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, config.Jobs, 2)
+			require.Equal(t, "asc", config.Jobs[0].Order)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package daemon_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/daemon"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// A Thursday.
+	from := time.Date(2021, time.July, 1, 10, 30, 15, 0, time.UTC)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2021, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{spec: "* * * * *", expected: at(time.July, 1, 10, 31)},
+		{spec: "0 3 * * *", expected: at(time.July, 2, 3, 0)},
+		{spec: "@hourly", expected: at(time.July, 1, 11, 0)},
+		{spec: "*/20 * * * *", expected: at(time.July, 1, 10, 40)},
+		{spec: "15,45 9-17 * * *", expected: at(time.July, 1, 10, 45)},
+		{spec: "0 0 * * 1-5", expected: at(time.July, 2, 0, 0)},
+		{spec: "@weekly", expected: at(time.July, 4, 0, 0)},
+		{spec: "0 12 * * 7", expected: at(time.July, 4, 12, 0)},
+		{spec: "@monthly", expected: at(time.August, 1, 0, 0)},
+		{spec: "0 0 29 2 *", expected: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when both are restricted.
+		{spec: "0 0 15 * 6", expected: at(time.July, 3, 0, 0)},
+		{spec: "0 0 31 4 *", expected: time.Time{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			s, err := daemon.ParseSchedule(tc.spec)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, s.Next(from))
+		})
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	testCases := []struct {
+		spec          string
+		expectedError string
+	}{
+		{spec: "* * * *", expectedError: `schedule "* * * *" must have 5 fields: minute hour day-of-month month day-of-week`},
+		{spec: "60 * * * *", expectedError: `the minute field "60" is out of its 0-59 range`},
+		{spec: "* 5-2 * * *", expectedError: `the hour field "5-2" is out of its 0-23 range`},
+		{spec: "* * 0 * *", expectedError: `the day of the month field "0" is out of its 1-31 range`},
+		{spec: "*/0 * * * *", expectedError: `invalid step in the minute field "*/0"`},
+		{spec: "* * * jan *", expectedError: `invalid value in the month field "jan"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			_, err := daemon.ParseSchedule(tc.spec)
+			require.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Package daemon runs reports on cron-like schedules, saving the results
+// of each run as a snapshot.
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/metrics"
+	"github.com/carlisia/ghinfo/snapshot"
+)
+
+// Daemon runs the jobs of a configuration when they are due. A job is
+// not started again while its previous run is still going.
+type Daemon struct {
+	gh      *github.Github
+	config  Config
+	store   snapshot.Store
+	metrics *metrics.Collector
+
+	mu      sync.Mutex
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+// New returns a daemon running the jobs of `config` with `gh`, saving
+// the snapshots to `store`.
+func New(gh *github.Github, config Config, store snapshot.Store) *Daemon {
+	d := &Daemon{
+		gh:      gh,
+		config:  config,
+		store:   store,
+		running: make(map[string]bool),
+	}
+	if config.MetricsFile != "" {
+		d.metrics = metrics.New()
+		gh.SetObserver(d.metrics)
+	}
+	return d
+}
+
+// Run runs the jobs on their schedules until `ctx` is done, then waits
+// for the running jobs, which are canceled along with `ctx`, to return.
+func (d *Daemon) Run(ctx context.Context) error {
+	next := make([]time.Time, len(d.config.Jobs))
+	now := time.Now()
+	for i, job := range d.config.Jobs {
+		next[i] = job.schedule.Next(now)
+		log.Printf("%s: the first run is at %s", job.Name, next[i].Format(time.RFC3339))
+	}
+
+	for {
+		var due time.Time
+		for _, t := range next {
+			if !t.IsZero() && (due.IsZero() || t.Before(due)) {
+				due = t
+			}
+		}
+		if due.IsZero() {
+			d.wg.Wait()
+			return fmt.Errorf("none of the schedules will run in the next years")
+		}
+
+		timer := time.NewTimer(time.Until(due))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			d.wg.Wait()
+			return nil
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		for i, job := range d.config.Jobs {
+			if next[i].IsZero() || next[i].After(now) {
+				continue
+			}
+			d.start(ctx, job)
+			next[i] = job.schedule.Next(now)
+		}
+	}
+}
+
+// start runs the job in the background, unless it is already running.
+func (d *Daemon) start(ctx context.Context, job Job) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running[job.Name] {
+		log.Printf("%s: the previous run is still going, skipping this one", job.Name)
+		return
+	}
+	d.running[job.Name] = true
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer func() {
+			d.mu.Lock()
+			d.running[job.Name] = false
+			d.mu.Unlock()
+		}()
+
+		start := time.Now()
+		path, err := d.runJob(ctx, job)
+		if err != nil {
+			log.Printf("%s: failed after %s: %v", job.Name, time.Since(start).Round(time.Second), err)
+			return
+		}
+		log.Printf("%s: done in %s, the snapshot was saved to %s", job.Name, time.Since(start).Round(time.Second), path)
+	}()
+}
+
+// runJob runs the report of the job once, and returns the path of the
+// snapshot it was saved to.
+func (d *Daemon) runJob(ctx context.Context, job Job) (string, error) {
+	since, maxID := job.Since, job.MaxID
+	if job.Window > 0 {
+		latest, err := d.gh.LatestID(ctx)
+		if err != nil {
+			return "", fmt.Errorf("error trying to find the latest repository ID: %w", err)
+		}
+		maxID = latest
+		since = latest - job.Window
+		if since < 0 {
+			since = 0
+		}
+	}
+
+	report, err := analytics.NewReport(job.reportType, job.options(since, maxID))
+	if err != nil {
+		return "", err
+	}
+	log.Printf("%s: running the %s for IDs %d to %d", job.Name, report.Name(), since, maxID)
+
+	err = report.Run(ctx, d.gh)
+	if d.metrics != nil {
+		d.metrics.RecordRun(report, err, time.Now())
+		if err := d.metrics.WriteFile(d.config.MetricsFile); err != nil {
+			log.Printf("%s: error trying to write the metrics: %v", job.Name, err)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	recorder, ok := report.(analytics.Recorder)
+	if !ok {
+		return "", fmt.Errorf("the %s cannot be saved as a snapshot", report.Name())
+	}
+	return d.store.Save(snapshot.New(recorder.Results(), time.Now()))
+}
@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/githubtest"
+	"github.com/carlisia/ghinfo/snapshot"
+)
+
+// newGithub serves `count` repositories with consecutive IDs from firstID.
+func newGithub(t *testing.T, firstID, count int) *github.Github {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 1, Count: count, FirstID: firstID, Owners: 10}))
+	t.Cleanup(server.Close)
+	return server.Github()
+}
+
+// Test_runJobWindow asserts that a job with a window runs over the
+// latest IDs, and is saved as a snapshot.
+func Test_runJobWindow(t *testing.T) {
+	job := Job{Name: "latest", Report: "stars", Schedule: "@daily", Window: 20}
+	config := Config{Jobs: []Job{job}}
+	require.NoError(t, config.validate())
+
+	store := snapshot.Store{Dir: t.TempDir()}
+	d := New(newGithub(t, 1_000_000, 100), config, store)
+
+	path, err := d.runJob(context.Background(), config.Jobs[0])
+	require.NoError(t, err)
+
+	s, err := store.Load(path)
+	require.NoError(t, err)
+	require.Equal(t, 1_000_079, s.Since)
+	require.Equal(t, 1_000_099, s.MaxID)
+	require.Len(t, s.Repos, 20)
+}
+
+// Test_startSkipsRunning asserts that a job is not started while its
+// previous run is still going.
+func Test_startSkipsRunning(t *testing.T) {
+	job := Job{Name: "fixed", Report: "stars", Schedule: "@daily", Since: 0, MaxID: 10}
+	config := Config{Jobs: []Job{job}}
+	require.NoError(t, config.validate())
+
+	store := snapshot.Store{Dir: t.TempDir()}
+	d := New(newGithub(t, 1, 100), config, store)
+
+	d.running["fixed"] = true
+	d.start(context.Background(), config.Jobs[0])
+	d.wg.Wait()
+	ids, err := store.List()
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	d.running["fixed"] = false
+	d.start(context.Background(), config.Jobs[0])
+	d.wg.Wait()
+	ids, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	require.False(t, d.running["fixed"])
+}
@@ -0,0 +1,147 @@
+// Package cassette records the responses of the GitHub API to a directory,
+// and plays them back, so that a run can be reproduced offline.
+//
+// Both the Recorder and the Player are github.HTTPClient implementations.
+// Responses are keyed by the request method, path and query, leaving the
+// host out, so a cassette recorded against api.github.com can be played back
+// against any base URL. Request headers, and so tokens, are not recorded.
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// ErrNotRecorded is returned when replaying a request that has no
+// recorded response.
+var ErrNotRecorded = errors.New("no recorded response")
+
+// notRecordedError is not retried by the GitHub client, since the
+// response won't be in the cassette any more on the next attempt.
+type notRecordedError struct {
+	msg string
+}
+
+func (e notRecordedError) Error() string   { return e.msg }
+func (e notRecordedError) Unwrap() error   { return ErrNotRecorded }
+func (e notRecordedError) Retryable() bool { return false }
+
+// episode is a recorded response, saved as one JSON file.
+type episode struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+// Recorder sends requests with Client, and records their responses to Dir.
+type Recorder struct {
+	Client github.HTTPClient
+	Dir    string
+}
+
+// NewRecorder returns a recorder sending requests with client, creating
+// the cassette directory when needed.
+func NewRecorder(client github.HTTPClient, dir string) (*Recorder, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Recorder{Client: client, Dir: dir}, nil
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	e := episode{
+		Method: req.Method,
+		URL:    req.URL.RequestURI(),
+		Status: resp.StatusCode,
+		Header: resp.Header,
+		Body:   string(body),
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(r.Dir, fileName(req)), data, 0o644); err != nil {
+		return nil, fmt.Errorf("error trying to record the response of %s: %w", e.URL, err)
+	}
+	return resp, nil
+}
+
+// Player serves the responses recorded to Dir, without sending any request.
+type Player struct {
+	Dir string
+}
+
+// NewPlayer returns a player of the cassette in dir.
+func NewPlayer(dir string) (*Player, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a cassette directory", dir)
+	}
+	return &Player{Dir: dir}, nil
+}
+
+func (p *Player) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(p.Dir, fileName(req)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, notRecordedError{fmt.Sprintf("%s for %s %s in %s", ErrNotRecorded, req.Method, req.URL.RequestURI(), p.Dir)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var e episode
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("error trying to read the recorded response of %s: %w", req.URL.RequestURI(), err)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header,
+		Body:          ioutil.NopCloser(bytes.NewReader([]byte(e.Body))),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}, nil
+}
+
+// fileName returns the file the response to req is recorded to.
+func fileName(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.RequestURI()))
+	return hex.EncodeToString(sum[:12]) + ".json"
+}
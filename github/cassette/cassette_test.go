@@ -0,0 +1,71 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/cassette"
+	"github.com/carlisia/ghinfo/github/githubtest"
+)
+
+// TestRecordReplay asserts that a paginated listing recorded from the API
+// is played back the same, without the API.
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 2, Count: 40, FirstID: 500, MaxGap: 3}))
+	server.PageSize = 6
+	query := github.Query{Since: 500, MaxID: server.Repos()[30].ID}
+
+	recorder, err := cassette.NewRecorder(server.Client(), dir)
+	require.NoError(t, err)
+	gh, err := github.New(recorder, server.URL, "test-user-agent")
+	require.NoError(t, err)
+
+	recorded, err := gh.QueryRepos(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, recorded, 30)
+	repo, err := gh.Repo(context.Background(), recorded[0].Owner.Login, recorded[0].Name)
+	require.NoError(t, err)
+	server.Close()
+
+	player, err := cassette.NewPlayer(dir)
+	require.NoError(t, err)
+	gh, err = github.New(player, "https://api.github.com", "test-user-agent")
+	require.NoError(t, err)
+
+	replayed, err := gh.QueryRepos(context.Background(), query)
+	require.NoError(t, err)
+	require.Equal(t, recorded, replayed)
+	replayedRepo, err := gh.Repo(context.Background(), repo.Owner.Login, repo.Name)
+	require.NoError(t, err)
+	require.Equal(t, repo, replayedRepo)
+
+	_, err = gh.Repo(context.Background(), "nobody", "nothing")
+	require.True(t, errors.Is(err, cassette.ErrNotRecorded), err)
+}
+
+// TestRecordErrors asserts that error responses are recorded as well.
+func TestRecordErrors(t *testing.T) {
+	dir := t.TempDir()
+	server := githubtest.NewServer(nil)
+
+	recorder, err := cassette.NewRecorder(server.Client(), dir)
+	require.NoError(t, err)
+	gh, err := github.New(recorder, server.URL, "test-user-agent")
+	require.NoError(t, err)
+	_, err = gh.Repo(context.Background(), "nobody", "nothing")
+	require.Error(t, err)
+	server.Close()
+
+	player, err := cassette.NewPlayer(dir)
+	require.NoError(t, err)
+	gh, err = github.New(player, server.URL, "test-user-agent")
+	require.NoError(t, err)
+	_, err = gh.Repo(context.Background(), "nobody", "nothing")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "404 Not Found")
+}
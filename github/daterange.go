@@ -0,0 +1,206 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// firstProbeID is where the search for an upper bound starts. Public
+// repository IDs are in the hundreds of millions, so the probe doubles
+// from here a handful of times before it crosses the window.
+const firstProbeID = 1 << 20
+
+// errNoRepos is returned by firstRepoSince when there are no
+// repositories after the given ID.
+var errNoRepos = errors.New("no repositories found after the given ID")
+
+// ErrRangeTooLong is returned by ResolveDateRangeWithin when the
+// repositories of the window span more IDs than allowed.
+var ErrRangeTooLong = errors.New("the range spans too many IDs")
+
+// RepoByID returns the full repository record, including its creation
+// timestamp, for the repository with the given ID.
+func (gh *Github) RepoByID(ctx context.Context, id int) (Repos, error) {
+	githubURL := gh.endpoint("/repositories/" + strconv.Itoa(id))
+
+	var repo Repos
+	if _, err := gh.do(ctx, githubURL.String(), &repo); err != nil {
+		return Repos{}, err
+	}
+	return repo, nil
+}
+
+// ResolveDateRange finds the repository ID range for repositories created
+// between `from` and `to`, inclusive. The returned query can be used as is
+// with QueryRepos: its `Since` is the last ID before the window and its
+// `MaxID` the last ID inside of it.
+//
+// The `/repositories` endpoint lists repositories by ascending ID, and IDs
+// are handed out as repositories are created, so each bound is found by
+// binary searching the `since` value against the creation timestamp of the
+// first repository returned for it.
+func (gh *Github) ResolveDateRange(ctx context.Context, from, to time.Time) (Query, error) {
+	return gh.ResolveDateRangeWithin(ctx, from, to, 0)
+}
+
+// ResolveDateRangeWithin is ResolveDateRange, failing with ErrRangeTooLong
+// as soon as the start of the range is found, when it is followed by more
+// than maxIDs IDs of repositories created in the window. The end of the
+// range, which takes as many requests to find as its start, isn't searched
+// then. maxIDs 0 doesn't limit the range.
+func (gh *Github) ResolveDateRangeWithin(ctx context.Context, from, to time.Time, maxIDs int) (Query, error) {
+	if to.Before(from) {
+		return Query{}, fmt.Errorf("the end of the date range (%s) cannot be before its start (%s)",
+			to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	r := dateResolver{gh: gh, ctx: ctx, created: make(map[int]time.Time)}
+
+	since, err := r.search(func(created time.Time) bool { return !created.Before(from) })
+	if err != nil {
+		return Query{}, err
+	}
+	if maxIDs > 0 {
+		created, err := r.firstCreatedSince(since + maxIDs)
+		if err != nil && !errors.Is(err, errNoRepos) {
+			return Query{}, err
+		}
+		if err == nil && !created.After(to) {
+			return Query{}, fmt.Errorf("%w: the repositories created in the window have IDs from %d to past %d, more than %d IDs",
+				ErrRangeTooLong, since+1, since+maxIDs, maxIDs)
+		}
+	}
+	maxID, err := r.search(func(created time.Time) bool { return created.After(to) })
+	if err != nil {
+		return Query{}, err
+	}
+
+	return Query{Since: since, MaxID: maxID}, nil
+}
+
+// dateResolver memoizes the creation timestamp of the first repository after
+// each probed ID, since both bounds of a range probe many of the same IDs.
+type dateResolver struct {
+	gh      *Github
+	ctx     context.Context
+	created map[int]time.Time
+}
+
+// search returns the smallest `since` value for which the first repository
+// listed after it satisfies `past`. When no repository does, the ID of the
+// newest repository is returned.
+func (r *dateResolver) search(past func(time.Time) bool) (int, error) {
+	lo, hi := 0, firstProbeID
+	for {
+		created, err := r.firstCreatedSince(hi)
+		if errors.Is(err, errNoRepos) {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		if past(created) {
+			break
+		}
+		lo, hi = hi, hi*2
+	}
+
+	// Invariant: the first repository after `lo` is not past the bound, the
+	// first one after `hi` is, or there is none.
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		created, err := r.firstCreatedSince(mid)
+		switch {
+		case errors.Is(err, errNoRepos):
+			hi = mid
+		case err != nil:
+			return 0, err
+		case past(created):
+			hi = mid
+		default:
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+func (r *dateResolver) firstCreatedSince(since int) (time.Time, error) {
+	if created, ok := r.created[since]; ok {
+		return created, nil
+	}
+
+	if err := r.ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+
+	first, err := r.gh.firstRepoSince(r.ctx, since)
+	if err != nil {
+		return time.Time{}, err
+	}
+	repo, err := r.gh.RepoByID(r.ctx, first.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	r.created[since] = repo.CreatedAt
+	return repo.CreatedAt, nil
+}
+
+// LatestID returns the ID of the newest repository, searching for the
+// smallest `since` value after which no repository is listed.
+func (gh *Github) LatestID(ctx context.Context) (int, error) {
+	hasReposAfter := func(since int) (bool, error) {
+		_, err := gh.firstRepoSince(ctx, since)
+		if errors.Is(err, errNoRepos) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	lo, hi := 0, firstProbeID
+	for {
+		found, err := hasReposAfter(hi)
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			break
+		}
+		lo, hi = hi, hi*2
+	}
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		found, err := hasReposAfter(mid)
+		if err != nil {
+			return 0, err
+		}
+		if found {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// firstRepoSince returns the first repository with an ID greater than since.
+func (gh *Github) firstRepoSince(ctx context.Context, since int) (Repos, error) {
+	githubURL := gh.endpoint("/repositories")
+
+	q := githubURL.Query()
+	q.Set("since", fmt.Sprint(since))
+	githubURL.RawQuery = q.Encode()
+
+	var repos []Repos
+	if _, err := gh.do(ctx, githubURL.String(), &repos); err != nil {
+		return Repos{}, err
+	}
+	if len(repos) == 0 {
+		return Repos{}, errNoRepos
+	}
+	return repos[0], nil
+}
@@ -0,0 +1,158 @@
+package github_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+var epoch = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+// newDatedServer serves repositories with IDs 10, 20, ..., up to lastID,
+// where repository `id` was created `id` minutes after `epoch`.
+func newDatedServer(t *testing.T, lastID int) *github.Github {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repositories", func(w http.ResponseWriter, r *http.Request) {
+		since, err := strconv.Atoi(r.URL.Query().Get("since"))
+		require.NoError(t, err)
+
+		repos := []github.Repos{}
+		for id := (since/10 + 1) * 10; id <= lastID && len(repos) < 100; id += 10 {
+			repos = append(repos, github.Repos{ID: id})
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(repos))
+	})
+	mux.HandleFunc("/repositories/", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/repositories/"))
+		require.NoError(t, err)
+		if id%10 != 0 || id > lastID {
+			http.NotFound(w, r)
+			return
+		}
+
+		repo := github.Repos{ID: id, CreatedAt: epoch.Add(time.Duration(id) * time.Minute)}
+		require.NoError(t, json.NewEncoder(w).Encode(repo))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	gh, err := github.New(nil, server.URL, "test-user-agent")
+	require.NoError(t, err)
+	return gh
+}
+
+// TestResolveDateRange asserts that a creation date window resolves to the
+// ID range holding exactly the repositories created inside of it.
+func TestResolveDateRange(t *testing.T) {
+	gh := newDatedServer(t, 5_000_000)
+
+	minute := func(n int) time.Time { return epoch.Add(time.Duration(n) * time.Minute) }
+
+	testCases := []struct {
+		name          string
+		from, to      time.Time
+		expected      github.Query
+		expectedError string
+	}{
+		{
+			name:     "bounds on existing repositories",
+			from:     minute(100),
+			to:       minute(200),
+			expected: github.Query{Since: 90, MaxID: 200},
+		},
+		{
+			name:     "bounds in between repositories",
+			from:     minute(95),
+			to:       minute(205),
+			expected: github.Query{Since: 90, MaxID: 200},
+		},
+		{
+			name:     "window with a single repository",
+			from:     minute(1_234_560),
+			to:       minute(1_234_560),
+			expected: github.Query{Since: 1_234_550, MaxID: 1_234_560},
+		},
+		{
+			name:     "window without repositories",
+			from:     minute(101),
+			to:       minute(109),
+			expected: github.Query{Since: 100, MaxID: 100},
+		},
+		{
+			name:     "window before the first repository",
+			from:     epoch.Add(-time.Hour),
+			to:       minute(5),
+			expected: github.Query{Since: 0, MaxID: 0},
+		},
+		{
+			name:     "window past the newest repository",
+			from:     minute(4_999_995),
+			to:       minute(6_000_000),
+			expected: github.Query{Since: 4_999_990, MaxID: 5_000_000},
+		},
+		{
+			name:          "end before start",
+			from:          minute(200),
+			to:            minute(100),
+			expectedError: "the end of the date range (2016-03-01T01:40:00Z) cannot be before its start (2016-03-01T03:20:00Z)",
+		},
+	}
+
+	for _, tc := range testCases {
+		f := func(t *testing.T) {
+			q, err := gh.ResolveDateRange(context.Background(), tc.from, tc.to)
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q)
+		}
+
+		t.Run(tc.name, f)
+	}
+}
+
+// TestResolveDateRangeWithin asserts that windows spanning more IDs than
+// allowed are rejected, and the other ones resolved as usual.
+func TestResolveDateRangeWithin(t *testing.T) {
+	gh := newDatedServer(t, 5_000_000)
+
+	minute := func(n int) time.Time { return epoch.Add(time.Duration(n) * time.Minute) }
+
+	q, err := gh.ResolveDateRangeWithin(context.Background(), minute(100), minute(590), 500)
+	require.NoError(t, err)
+	require.Equal(t, github.Query{Since: 90, MaxID: 590}, q)
+
+	_, err = gh.ResolveDateRangeWithin(context.Background(), minute(100), minute(600), 500)
+	require.True(t, errors.Is(err, github.ErrRangeTooLong), err)
+	require.EqualError(t, err, "the range spans too many IDs: the repositories created in the window have IDs from 91 to past 590, more than 500 IDs")
+
+	// Up to the newest repository.
+	q, err = gh.ResolveDateRangeWithin(context.Background(), minute(4_999_800), minute(6_000_000), 500)
+	require.NoError(t, err)
+	require.Equal(t, github.Query{Since: 4_999_790, MaxID: 5_000_000}, q)
+}
+
+// TestLatestID asserts that the newest repository is found.
+func TestLatestID(t *testing.T) {
+	for _, lastID := range []int{10, 1_048_580, 5_000_000} {
+		gh := newDatedServer(t, lastID)
+
+		id, err := gh.LatestID(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, lastID, id)
+	}
+}
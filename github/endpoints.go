@@ -4,26 +4,42 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"net/url"
-	"os"
 	"sort"
 
 	"github.com/pkg/errors"
 )
 
 // QuerySearchRepos (not used) returns search results for the repository endpoint, based on queries.
+// The `q` parameter is taken from `query.Q`, see SearchQuery, and defaults to public
+// repositories with 500 to 1000 stars.
 // The results are returned on a best effort basis, and unfortunately is
 // not as reliable as going against the endpoint for the repository resource.
 func (gh *Github) QuerySearchRepos(ctx context.Context, query Query) ([]Repos, error) {
-	endPoint := url.URL{Path: "/search/repositories"}
-	githubURL := gh.baseURL.ResolveReference(&endPoint)
+	githubURL := gh.endpoint("/search/repositories")
+
+	search := query.Q
+	if search == "" {
+		var err error
+		search, err = NewSearchQuery().Public().Stars(500, 1000).Build()
+		if err != nil {
+			return nil, err
+		}
+	}
+	sortBy := query.Sort
+	if sortBy == "" {
+		sortBy = "stars"
+	}
+	order := query.Order
+	if order == "" {
+		order = "asc"
+	}
 
 	q := githubURL.Query()
-	q.Set("q", "is:public stars:500..1000")
-	q.Set("sort", "stars")
+	q.Set("q", search)
+	q.Set("sort", sortBy)
 	q.Set("per_page", "100")
 	q.Set("since", fmt.Sprint(query.Since))
-	q.Set("order", "asc")
+	q.Set("order", order)
 	githubURL.RawQuery = q.Encode()
 	requestPath := githubURL.String()
 
@@ -46,21 +62,15 @@ func (gh *Github) QuerySearchRepos(ctx context.Context, query Query) ([]Repos, e
 		return repos
 	}
 
-	i := 1
 	var allRepos []Repos
 	hasNextPage := true
 	for hasNextPage {
-		fmt.Fprintln(os.Stderr, "\t"+requestPath)
-		fmt.Println("Page number:", i)
-		i++
-
 		var data Data
-		resp, err := gh.do(requestPath, &data)
+		resp, err := gh.do(ctx, requestPath, &data)
 		if err != nil {
 			return nil, err
 		}
 
-		fmt.Println("data repo count:", len(data.Repos))
 		var curatedRepos []Repos
 		if curatedRepos = curateRepos(data.Repos); curatedRepos == nil {
 			break
@@ -73,65 +83,34 @@ func (gh *Github) QuerySearchRepos(ctx context.Context, query Query) ([]Repos, e
 
 // QueryRepos returns a list of public GH repositories. It starts the query based on the
 // value of the `since` parameter, and it stops and trims the returned results based
-// on the specified `maxID`.
+// on the specified `maxID`. Use IterateRepos to process the repositories as they are
+// listed instead.
 //
 // Note that this endpoint does not respect the (asc/desc) direction parameter, but does
 // return the elements in ascending order.
 func (gh *Github) QueryRepos(ctx context.Context, query Query) ([]Repos, error) {
-	endPoint := url.URL{Path: "/repositories"}
-	githubURL := gh.baseURL.ResolveReference(&endPoint)
-
-	q := githubURL.Query()
-	q.Set("since", fmt.Sprint(query.Since))
-	githubURL.RawQuery = q.Encode()
-
-	requestPath := githubURL.String()
 	var allRepos []Repos
-
-	curateRepos := func(repos []Repos) []Repos {
-		if len(repos) == 0 {
-			return nil
-		}
-
-		if rangeTooHigh(repos[0].ID, query.MaxID) {
-			return nil
-		}
-		lastID := repos[len(repos)-1].ID
-		fmt.Println("Repo ID for the last record in this page:", lastID)
-		if lastID == query.MaxID {
-			return repos
-		}
-		if lastID >= query.MaxID {
-			// Exclude additional repos that don't meet the cutoff max id.
-			return trimUpToMaxID(query.MaxID, repos)
-		}
-		return repos
+	it := gh.IterateRepos(ctx, query)
+	for it.Next() {
+		allRepos = append(allRepos, it.Repo())
 	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return allRepos, nil
+}
 
-	hasNextPage := true
-	for hasNextPage {
-		fmt.Fprintln(os.Stderr, "\t"+requestPath)
-
-		var repos []Repos
-		resp, err := gh.do(requestPath, &repos)
-		if err != nil {
-			return nil, err
-		}
-		fmt.Printf("\nReturned records for this page: %d\n", len(repos))
-
-		var curatedRepos []Repos
-		if curatedRepos = curateRepos(repos); curatedRepos != nil {
-			allRepos = append(allRepos, curatedRepos...)
-			lastID := repos[len(allRepos)-1].ID
-			if lastID == query.MaxID {
-				break
-			}
-		}
+// Repo returns the full repository record, which includes its star count
+// and license, for the repository `owner/name`.
+func (gh *Github) Repo(ctx context.Context, owner, name string) (Repos, error) {
+	path := "/repos" + "/" + owner + "/" + name
+	githubURL := gh.endpoint(path)
 
-		requestPath, hasNextPage = findNextPage(resp)
+	var repo Repos
+	if _, err := gh.do(ctx, githubURL.String(), &repo); err != nil {
+		return Repos{}, errors.Wrapf(err, "-- not possible to retrieve login: %s/ name: %s", owner, name)
 	}
-
-	return allRepos, nil
+	return repo, nil
 }
 
 func (gh *Github) QueryStars(ctx context.Context, repos []Repos) (map[string]map[int]int, []error) {
@@ -142,14 +121,13 @@ func (gh *Github) QueryStars(ctx context.Context, repos []Repos) (map[string]map
 
 	for i := range repos {
 		path := "/repos" + "/" + repos[i].Owner.Login + "/" + repos[i].Name
-		endPoint := url.URL{Path: path}
-		githubURL := gh.baseURL.ResolveReference(&endPoint)
+		githubURL := gh.endpoint(path)
 
 		var star struct {
 			Count int `json:"stargazers_count"`
 		}
 
-		res, err := gh.do(githubURL.String(), &star)
+		res, err := gh.do(ctx, githubURL.String(), &star)
 		if res != nil {
 			success := res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices
 			if !success {
@@ -161,9 +139,9 @@ func (gh *Github) QueryStars(ctx context.Context, repos []Repos) (map[string]map
 			err = errors.Wrapf(err, fmt.Sprintf("-- not possible to retrieve startgazers for login: %s/ name: %s", repos[i].Owner.Login, repos[i].Name))
 			errs = append(errs, err)
 		}
-		bucketTierRepoCount[bucketTier(star.Count)]++
-		bucketTierStarCount[bucketTier(star.Count)] += star.Count
-		buckets[bucketTier(star.Count)] = map[int]int{bucketTierRepoCount[bucketTier(star.Count)]: bucketTierStarCount[bucketTier(star.Count)]}
+		bucketTierRepoCount[BucketTier(star.Count)]++
+		bucketTierStarCount[BucketTier(star.Count)] += star.Count
+		buckets[BucketTier(star.Count)] = map[int]int{bucketTierRepoCount[BucketTier(star.Count)]: bucketTierStarCount[BucketTier(star.Count)]}
 	}
 
 	return buckets, errs
@@ -174,8 +152,7 @@ func (gh *Github) QueryLicenses(ctx context.Context, repos []Repos) map[string]i
 
 	for i := range repos {
 		path := "/repos" + "/" + repos[i].Owner.Login + "/" + repos[i].Name + "/license"
-		endPoint := url.URL{Path: path}
-		githubURL := gh.baseURL.ResolveReference(&endPoint)
+		githubURL := gh.endpoint(path)
 
 		var data struct {
 			Name    string  `json:"name"`
@@ -184,7 +161,7 @@ func (gh *Github) QueryLicenses(ctx context.Context, repos []Repos) map[string]i
 
 		var res *http.Response
 		var err error
-		res, err = gh.do(githubURL.String(), &data)
+		res, err = gh.do(ctx, githubURL.String(), &data)
 		if res != nil {
 			success := res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices
 			if !success {
@@ -2,22 +2,76 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// Observer is notified of the requests made to the GitHub API, e.g. to
+// export them as metrics.
+type Observer interface {
+	// ObserveRequest is called once per attempt, with a 0 status when
+	// no response was received.
+	ObserveRequest(endpoint string, status int, latency time.Duration)
+	// ObserveRetry is called before an attempt is retried, with the
+	// reason it is retried for, one of RetryServerError,
+	// RetryRateLimited and RetryNoResponse.
+	ObserveRetry(endpoint, reason string)
+	// ObserveRateLimit is called with the remaining requests of the
+	// rate limit window, when the response includes them.
+	ObserveRateLimit(remaining int)
+}
+
+type nopObserver struct{}
+
+func (nopObserver) ObserveRequest(string, int, time.Duration) {}
+func (nopObserver) ObserveRetry(string, string)               {}
+func (nopObserver) ObserveRateLimit(int)                      {}
+
+// observers notifies each of its observers in turn.
+type observers []Observer
+
+func (obs observers) ObserveRequest(endpoint string, status int, latency time.Duration) {
+	for _, o := range obs {
+		o.ObserveRequest(endpoint, status, latency)
+	}
+}
+
+func (obs observers) ObserveRetry(endpoint, reason string) {
+	for _, o := range obs {
+		o.ObserveRetry(endpoint, reason)
+	}
+}
+
+func (obs observers) ObserveRateLimit(remaining int) {
+	for _, o := range obs {
+		o.ObserveRateLimit(remaining)
+	}
+}
+
+// DefaultBaseURL is the REST API of github.com.
+const DefaultBaseURL = "https://api.github.com"
+
 type Github struct {
-	client    HTTPClient
-	baseURL   *url.URL
-	userAgent string
+	client     HTTPClient
+	baseURL    *url.URL
+	graphQLURL string
+	userAgent  string
+	observer   Observer
 }
 
+// New returns a client of the REST API at baseURL, DefaultBaseURL or the
+// `/api/v3` URL of a GitHub Enterprise Server, which endpoint paths are
+// appended to.
 func New(httpClient HTTPClient, baseURL string, userAgent string) (*Github, error) {
 	if httpClient == nil {
 		httpClient = &http.Client{}
@@ -27,39 +81,161 @@ func New(httpClient HTTPClient, baseURL string, userAgent string) (*Github, erro
 	if err != nil {
 		return nil, err
 	}
+	githubURL.Path = strings.TrimSuffix(githubURL.Path, "/")
 
 	gh := Github{
-		client:    httpClient,
-		baseURL:   githubURL,
-		userAgent: userAgent,
+		client:     httpClient,
+		baseURL:    githubURL,
+		graphQLURL: GraphQLURL(githubURL.String()),
+		userAgent:  userAgent,
+		observer:   nopObserver{},
 	}
 	return &gh, nil
 }
 
-// do only processes `GET` requests.
-func (gh *Github) do(url string, data interface{}) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+// NormalizeBaseURL returns the REST API URL of `raw`, which is either an
+// API URL, or the URL of a GitHub Enterprise Server, e.g.
+// https://github.example.com, whose API is served under `/api/v3`.
+func NormalizeBaseURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("%q is not an http(s) URL", raw)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if u.Path == "" && u.Host != "api.github.com" {
+		u.Path = "/api/v3"
+	}
+	return u.String(), nil
+}
+
+// NormalizeGraphQLURL returns the GraphQL API URL `raw` without its
+// trailing slash. Unlike the REST API URL, it is taken as is otherwise.
+func NormalizeGraphQLURL(raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("%q is not an http(s) URL", raw)
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String(), nil
+}
+
+// GraphQLURL returns the GraphQL endpoint that goes along with the REST
+// API at baseURL: `/graphql` on github.com, and `/api/graphql` on a
+// GitHub Enterprise Server.
+func GraphQLURL(baseURL string) string {
+	if strings.HasSuffix(baseURL, "/api/v3") {
+		return strings.TrimSuffix(baseURL, "/v3") + "/graphql"
+	}
+	return strings.TrimSuffix(baseURL, "/") + "/graphql"
+}
+
+// SetObserver sets the observer notified of every request, replacing the
+// ones already set.
+func (gh *Github) SetObserver(o Observer) {
+	gh.observer = o
+}
+
+// AddObserver adds an observer notified of every request, after the ones
+// already set, e.g. to show the progress of a run along with its metrics.
+func (gh *Github) AddObserver(o Observer) {
+	if _, ok := gh.observer.(nopObserver); ok {
+		gh.observer = o
+		return
+	}
+	gh.observer = observers{gh.observer, o}
+}
+
+// BaseURL returns the URL of the REST API.
+func (gh *Github) BaseURL() string {
+	return gh.baseURL.String()
+}
+
+// GraphQLURL returns the URL of the GraphQL API, derived from the base URL
+// unless set with SetGraphQLURL.
+func (gh *Github) GraphQLURL() string {
+	return gh.graphQLURL
+}
+
+// SetGraphQLURL sets the URL of the GraphQL API, for GitHub Enterprise
+// Servers serving it elsewhere than next to the REST API.
+func (gh *Github) SetGraphQLURL(u string) {
+	gh.graphQLURL = u
+}
+
+// endpoint returns the URL of the API endpoint at path, e.g.
+// `/repositories`. Unlike resolving the path against the base URL, it
+// keeps the `/api/v3` prefix of GitHub Enterprise Servers.
+func (gh *Github) endpoint(path string) *url.URL {
+	u := *gh.baseURL
+	u.Path += path
+	return &u
+}
+
+// do only processes `GET` requests. The request is canceled
+// along with `ctx`, and retried on server errors.
+func (gh *Github) do(ctx context.Context, url string, data interface{}) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", gh.userAgent)
+	endpoint := endpointLabel(strings.TrimPrefix(req.URL.Path, gh.baseURL.Path))
+
+	return gh.retry(ctx, endpoint, func() (*http.Response, bool, error) {
+		return gh.attempt(req, endpoint, data)
+	})
+}
 
+// attempt sends the request once, observing it, and reports whether it
+// may succeed when retried.
+func (gh *Github) attempt(req *http.Request, endpoint string, data interface{}) (*http.Response, bool, error) {
+	start := time.Now()
 	resp, err := gh.client.Do(req)
 	if err != nil {
-		return nil, err
+		gh.observer.ObserveRequest(endpoint, 0, time.Since(start))
+		return nil, retryable(err), err
 	}
 	defer resp.Body.Close()
+	gh.observer.ObserveRequest(endpoint, resp.StatusCode, time.Since(start))
+	// GitHub Enterprise Servers with rate limiting disabled don't send
+	// the headers at all.
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		gh.observer.ObserveRateLimit(remaining)
+	}
 
 	success := resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices
 	if !success {
-		return nil, fmt.Errorf("something went wrong with the request: %s", resp.Status)
+		retry, err := statusError(resp)
+		return nil, retry, err
 	}
 
 	// TODO: Use Unmarshal
 	if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	return resp, nil
+	return resp, false, nil
+}
+
+// endpointLabel returns the API endpoint of a request path, with the
+// owner, name and ID segments replaced by placeholders, so that every
+// repository falls under the same endpoint.
+func endpointLabel(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) >= 3 && segments[0] == "repos":
+		segments[1], segments[2] = "{owner}", "{repo}"
+	case len(segments) == 2 && segments[0] == "repositories":
+		segments[1] = "{id}"
+	}
+	return "/" + strings.Join(segments, "/")
 }
@@ -0,0 +1,106 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingObserver struct {
+	statuses  []int
+	retries   []string
+	remaining []int
+}
+
+func (o *recordingObserver) ObserveRequest(endpoint string, status int, _ time.Duration) {
+	o.statuses = append(o.statuses, status)
+}
+func (o *recordingObserver) ObserveRetry(_, reason string) {
+	o.retries = append(o.retries, reason)
+}
+func (o *recordingObserver) ObserveRateLimit(remaining int) {
+	o.remaining = append(o.remaining, remaining)
+}
+
+// Test_doObserver asserts that every attempt of a request is observed,
+// with its status, 0 when no response was received, each retry with its
+// reason, and the remaining rate limit when the response includes it.
+func Test_doObserver(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			// Closing the connection leaves the attempt without a response.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Header().Set("X-RateLimit-Remaining", "42")
+			w.Write([]byte(`{"id": 1}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	gh, err := New(nil, server.URL, "test-user-agent")
+	require.NoError(t, err)
+	observer := &recordingObserver{}
+	gh.SetObserver(observer)
+
+	_, err = gh.RepoByID(context.Background(), 1)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, http.StatusBadGateway, http.StatusOK}, observer.statuses)
+	require.Equal(t, []string{RetryNoResponse, RetryServerError}, observer.retries)
+	require.Equal(t, []int{42}, observer.remaining)
+}
+
+// TestAddObserver asserts that an added observer is notified along with
+// the one already set.
+func TestAddObserver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	gh, err := New(nil, server.URL, "test-user-agent")
+	require.NoError(t, err)
+	first, second := &recordingObserver{}, &recordingObserver{}
+	gh.AddObserver(first)
+	require.Equal(t, first, gh.observer)
+	gh.AddObserver(second)
+
+	_, err = gh.RepoByID(context.Background(), 1)
+	require.NoError(t, err)
+	for _, o := range []*recordingObserver{first, second} {
+		require.Equal(t, []int{http.StatusOK}, o.statuses)
+		require.Equal(t, []int{42}, o.remaining)
+	}
+}
+
+func Test_endpointLabel(t *testing.T) {
+	require.Equal(t, "/repositories", endpointLabel("/repositories"))
+	require.Equal(t, "/repositories/{id}", endpointLabel("/repositories/123"))
+	require.Equal(t, "/repos/{owner}/{repo}", endpointLabel("/repos/octo/hello"))
+	require.Equal(t, "/repos/{owner}/{repo}/license", endpointLabel("/repos/octo/hello/license"))
+	require.Equal(t, "/search/repositories", endpointLabel("/search/repositories"))
+}
+
+func Test_endpoint(t *testing.T) {
+	gh, err := New(nil, "https://github.example.com/api/v3/", "test-user-agent")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.example.com/api/v3/repositories", gh.endpoint("/repositories").String())
+
+	gh, err = New(nil, DefaultBaseURL, "test-user-agent")
+	require.NoError(t, err)
+	require.Equal(t, "https://api.github.com/repos/octo/hello", gh.endpoint("/repos/octo/hello").String())
+}
@@ -1,115 +1,101 @@
 package github_test
 
 import (
-	"bytes"
 	"context"
-	"io/ioutil"
 	"net/http"
-	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/githubtest"
 )
 
-func newUnitTest(t *testing.T) (*github.Github, func()) {
-	mux := http.NewServeMux()
-	server := httptest.NewServer(mux)
-	teardown := func() {
-		server.Close()
+// newFake serves repositories with the given IDs.
+func newFake(t *testing.T, ids ...int) *githubtest.Server {
+	repos := make([]github.Repos, len(ids))
+	for i, id := range ids {
+		repos[i] = github.Repos{ID: id}
 	}
 
-	gh, err := github.New(&mockClient{}, server.URL, "test-user-agent")
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	return gh, teardown
-}
-
-type payload struct {
-	body   []byte
-	status int
+	server := githubtest.NewServer(repos)
+	t.Cleanup(server.Close)
+	return server
 }
 
 // TestQueryRepos asserts that the list of GH repositories being
 // returned by this call is consistent with the query criteria.
 func TestQueryRepos(t *testing.T) {
-	gh, teardown := newUnitTest(t)
-	t.Cleanup(teardown)
-
 	testCases := []struct {
 		name          string
-		payload       payload
+		ids           []int
 		paging        github.Query
 		reposExpected []github.Repos
-		expectedError string
 	}{
 		{
 			name:          "1 repo, id is in betweek since and max id",
-			payload:       payload{body: []byte(`[{"id": 5}]`), status: http.StatusOK},
+			ids:           []int{5},
 			paging:        github.Query{Since: 1, MaxID: 10},
 			reposExpected: []github.Repos{{ID: 5}},
 		},
 		{
 			name:          "1 repo, id is higher than since and max id",
-			payload:       payload{body: []byte(`[{"id": 10}]`), status: http.StatusOK},
+			ids:           []int{10},
 			paging:        github.Query{Since: 1, MaxID: 5},
 			reposExpected: []github.Repos{},
 		},
 		{
 			name:          "2 repos, both with ids after since and below max id",
-			payload:       payload{body: []byte(`[{"id": 4}, {"id": 7}]`), status: http.StatusOK},
+			ids:           []int{4, 7},
 			paging:        github.Query{Since: 1, MaxID: 10},
 			reposExpected: []github.Repos{{ID: 4}, {ID: 7}},
 		},
 
 		{
 			name:          "3 repos, last one has max id",
-			payload:       payload{body: []byte(`[{"id": 2}, {"id": 3}, {"id": 5}]`), status: http.StatusOK},
+			ids:           []int{2, 3, 5},
 			paging:        github.Query{Since: 1, MaxID: 5},
 			reposExpected: []github.Repos{{ID: 2}, {ID: 3}, {ID: 5}},
 		},
 		{
 			name:          "1 repos, id equal to max id",
-			payload:       payload{body: []byte(`[{"id": 6}]`), status: http.StatusOK},
+			ids:           []int{6},
 			paging:        github.Query{Since: 1, MaxID: 6},
 			reposExpected: []github.Repos{{ID: 6}},
 		},
 		{
 			name:          "1 repos, id one less than max id",
-			payload:       payload{body: []byte(`[{"id": 5}]`), status: http.StatusOK},
+			ids:           []int{5},
 			paging:        github.Query{Since: 1, MaxID: 6},
 			reposExpected: []github.Repos{{ID: 5}},
 		},
 		{
 			name:          "1 repos, id one higher than max id",
-			payload:       payload{body: []byte(`[{"id": 7}]`), status: http.StatusOK},
+			ids:           []int{7},
 			paging:        github.Query{Since: 1, MaxID: 6},
 			reposExpected: []github.Repos{},
 		},
 		{
 			name:          "1 repos, id one higher than max id",
-			payload:       payload{body: []byte(`[{"id": 7}]`), status: http.StatusOK},
+			ids:           []int{7},
 			paging:        github.Query{Since: 1, MaxID: 6},
 			reposExpected: []github.Repos{},
 		},
 		{
 			name:          "4 repos, last one with id higher than max id and none matches the max id",
-			payload:       payload{body: []byte(`[{"id": 2}, {"id": 3}, {"id": 5}, {"id": 50}]`), status: http.StatusOK},
+			ids:           []int{2, 3, 5, 50},
 			paging:        github.Query{Since: 1, MaxID: 10},
 			reposExpected: []github.Repos{{ID: 2}, {ID: 3}, {ID: 5}},
 		},
 		{
 			name:          "4 repos, one with id higher than max id, and next to the last same as max id",
-			payload:       payload{body: []byte(`[{"id": 2}, {"id": 3}, {"id": 10}, {"id": 50}]`), status: http.StatusOK},
+			ids:           []int{2, 3, 10, 50},
 			paging:        github.Query{Since: 1, MaxID: 10},
 			reposExpected: []github.Repos{{ID: 2}, {ID: 3}, {ID: 10}},
 		},
 		{
 			name:          "3 repos, last one with id same as max",
-			payload:       payload{body: []byte(`[{"id": 3}, {"id": 10}, {"id": 30}]`), status: http.StatusOK},
+			ids:           []int{3, 10, 30},
 			paging:        github.Query{Since: 1, MaxID: 30},
 			reposExpected: []github.Repos{{ID: 3}, {ID: 10}, {ID: 30}},
 		},
@@ -117,15 +103,13 @@ func TestQueryRepos(t *testing.T) {
 
 	for _, tc := range testCases {
 		f := func(t *testing.T) {
-			doFunc = mockResponse(tc.payload)
+			gh := newFake(t, tc.ids...).Github()
 			repos, err := gh.QueryRepos(context.Background(), tc.paging)
 			require.NoError(t, err, "failed to make a request")
 
-			if tc.payload.status == http.StatusOK {
-				require.Len(t, repos, len(tc.reposExpected))
-				for k := range tc.reposExpected {
-					require.Equal(t, tc.reposExpected[k], repos[k])
-				}
+			require.Len(t, repos, len(tc.reposExpected))
+			for k := range tc.reposExpected {
+				require.Equal(t, tc.reposExpected[k].ID, repos[k].ID)
 			}
 		}
 
@@ -133,30 +117,106 @@ func TestQueryRepos(t *testing.T) {
 	}
 }
 
-// mockResponse builds and returns a fake http response.
-func mockResponse(payload payload) func(req *http.Request) (*http.Response, error) {
-	return func(*http.Request) (*http.Response, error) {
-		// Create a new reader with the JSON input.
-		r := ioutil.NopCloser(bytes.NewReader([]byte(payload.body)))
-		return &http.Response{
-			Header: http.Header{
-				"Accept":     {"application/vnd.github.v3+json"},
-				"User-Agent": {"https://github.com/carlisia/ghinfo"},
-			},
-			StatusCode: payload.status,
-			Body:       r,
-		}, nil
+// TestQueryReposPages asserts that the listing is followed across pages,
+// up to the max ID.
+func TestQueryReposPages(t *testing.T) {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 1, Count: 50, FirstID: 1000, MaxGap: 5}))
+	t.Cleanup(server.Close)
+	server.PageSize = 7
+
+	all := server.Repos()
+	since, maxID := all[3].ID, all[40].ID
+
+	repos, err := server.Github().QueryRepos(context.Background(), github.Query{Since: since, MaxID: maxID})
+	require.NoError(t, err)
+	require.Len(t, repos, 37)
+	for i, repo := range repos {
+		require.Equal(t, all[4+i].ID, repo.ID)
+		require.Equal(t, all[4+i].FullName, repo.FullName)
 	}
+	require.Equal(t, 6, server.Requests("/repositories"))
 }
 
-// doFunc is he mock client's response for the `Do` function.
-var doFunc func(req *http.Request) (*http.Response, error)
+// TestQueryReposErrors asserts that failed pages are reported.
+func TestQueryReposErrors(t *testing.T) {
+	server := newFake(t, 1, 2, 3, 4, 5)
+	server.PageSize = 2
+	server.RateLimit = 2
 
-type mockClient struct {
-	DoFunc func(req *http.Request) (*http.Response, error)
+	_, err := server.Github().QueryRepos(context.Background(), github.Query{Since: 0, MaxID: 5})
+	require.EqualError(t, err, "something went wrong with the request: 403 Forbidden")
+
+	server = newFake(t, 1, 2, 3)
+	server.FailNext("/repositories", http.StatusNotFound)
+	_, err = server.Github().QueryRepos(context.Background(), github.Query{Since: 0, MaxID: 3})
+	require.EqualError(t, err, "something went wrong with the request: 404 Not Found")
+}
+
+// TestEnterpriseServer asserts that the `/api/v3` prefix of a GitHub
+// Enterprise Server is kept on every endpoint, and that a server without
+// rate limit headers can be crawled.
+func TestEnterpriseServer(t *testing.T) {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 1, Count: 12, FirstID: 100, Owners: 3}))
+	t.Cleanup(server.Close)
+	server.PathPrefix = "/api/v3"
+	server.PageSize = 5
+	server.RateLimit = 0
+
+	gh := server.Github()
+	require.Equal(t, server.URL+"/api/graphql", gh.GraphQLURL())
+
+	all := server.Repos()
+	repos, err := gh.QueryRepos(context.Background(), github.Query{Since: 0, MaxID: all[11].ID})
+	require.NoError(t, err)
+	require.Len(t, repos, 12)
+	require.Equal(t, 3, server.Requests("/repositories"))
+
+	repo, err := gh.Repo(context.Background(), all[0].Owner.Login, all[0].Name)
+	require.NoError(t, err)
+	require.Equal(t, all[0].StargazersCount, repo.StargazersCount)
 }
 
-// Do is the `Do` function for the mock client.
-func (m *mockClient) Do(req *http.Request) (*http.Response, error) {
-	return doFunc(req)
+func TestNormalizeBaseURL(t *testing.T) {
+	testCases := map[string]string{
+		"https://api.github.com":               "https://api.github.com",
+		"https://api.github.com/":              "https://api.github.com",
+		"https://github.example.com":           "https://github.example.com/api/v3",
+		"https://github.example.com/":          "https://github.example.com/api/v3",
+		"https://github.example.com/api/v3/":   "https://github.example.com/api/v3",
+		"http://localhost:8080/custom/prefix/": "http://localhost:8080/custom/prefix",
+	}
+	for raw, expected := range testCases {
+		actual, err := github.NormalizeBaseURL(raw)
+		require.NoError(t, err, raw)
+		require.Equal(t, expected, actual, raw)
+	}
+
+	_, err := github.NormalizeBaseURL("github.example.com")
+	require.Error(t, err)
+
+	require.Equal(t, "https://api.github.com/graphql", github.GraphQLURL(github.DefaultBaseURL))
+	require.Equal(t, "https://github.example.com/api/graphql", github.GraphQLURL("https://github.example.com/api/v3"))
+}
+
+func TestNormalizeGraphQLURL(t *testing.T) {
+	actual, err := github.NormalizeGraphQLURL("https://github.example.com/api/graphql/")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.example.com/api/graphql", actual)
+
+	_, err = github.NormalizeGraphQLURL("github.example.com/api/graphql")
+	require.Error(t, err)
+
+	gh, err := github.New(nil, "https://github.example.com/api/v3", "test-user-agent")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.example.com/api/graphql", gh.GraphQLURL())
+	gh.SetGraphQLURL("https://graphql.example.com")
+	require.Equal(t, "https://graphql.example.com", gh.GraphQLURL())
+}
+
+func TestBucketTierIndex(t *testing.T) {
+	for i, tier := range github.BucketTiers {
+		require.Equal(t, i, github.BucketTierIndex(tier))
+	}
+	require.Equal(t, len(github.BucketTiers), github.BucketTierIndex("unknown"))
+	require.Less(t, github.BucketTierIndex(github.BucketTier(5)), github.BucketTierIndex(github.BucketTier(5000)))
 }
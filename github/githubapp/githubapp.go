@@ -0,0 +1,198 @@
+// Package githubapp authenticates as a GitHub App installation: a JWT
+// signed with the private key of the app is exchanged for short lived
+// installation access tokens, which are refreshed before they expire.
+//
+// The token source plugs into an oauth2 client, whose requests are then
+// made on behalf of the installation:
+//
+//	ts, err := githubapp.NewTokenSource(githubapp.Config{...})
+//	gh, err := github.New(oauth2.NewClient(ctx, ts), baseURL, userAgent)
+package githubapp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// jwtLifetime is how long the signed JWTs are valid, GitHub accepting at
+// most 10 minutes.
+const jwtLifetime = 9 * time.Minute
+
+// clockSkew is how far back the JWTs are issued, in case the clock of
+// GitHub is behind.
+const clockSkew = time.Minute
+
+// RefreshBefore is how long before its expiry an installation token is
+// replaced, so that a request never goes out with an expired one.
+var RefreshBefore = 5 * time.Minute
+
+// Config identifies the installation to authenticate as.
+type Config struct {
+	// Client sends the token requests, http.DefaultClient when nil. It
+	// must not be authenticated with the token source itself.
+	Client github.HTTPClient
+	// BaseURL is the REST API URL, github.DefaultBaseURL when empty.
+	BaseURL        string
+	UserAgent      string
+	AppID          int64
+	InstallationID int64
+	Key            *rsa.PrivateKey
+
+	// now is overridden by tests.
+	now func() time.Time
+}
+
+// NewTokenSource returns a source of installation tokens, each reused
+// until it is about to expire.
+func NewTokenSource(c Config) (oauth2.TokenSource, error) {
+	if c.AppID == 0 || c.InstallationID == 0 {
+		return nil, errors.New("both the app ID and the installation ID are needed")
+	}
+	if c.Key == nil {
+		return nil, errors.New("the private key of the app is needed")
+	}
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = github.DefaultBaseURL
+	}
+	if c.now == nil {
+		c.now = time.Now
+	}
+	return oauth2.ReuseTokenSource(nil, installationTokens{c}), nil
+}
+
+// installationTokens requests a new installation token on every call.
+type installationTokens struct {
+	Config
+}
+
+func (s installationTokens) Token() (*oauth2.Token, error) {
+	jwt, err := SignJWT(s.AppID, s.Key, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", strings.TrimSuffix(s.BaseURL, "/"), s.InstallationID)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	if s.UserAgent != "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to get an installation token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		var body struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return nil, fmt.Errorf("error trying to get an installation token: %s %s", resp.Status, body.Message)
+	}
+
+	var token struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("error trying to read the installation token: %w", err)
+	}
+	return &oauth2.Token{
+		AccessToken: token.Token,
+		TokenType:   "token",
+		// oauth2 considers the token expired this much earlier, and
+		// asks for a new one.
+		Expiry: token.ExpiresAt.Add(-RefreshBefore),
+	}, nil
+}
+
+// SignJWT returns a JWT authenticating as the app, valid for a few
+// minutes from now, signed with RS256.
+func SignJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-clockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	signed := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + enc.EncodeToString(signature), nil
+}
+
+// ParsePrivateKey parses the PEM private key downloaded from the settings
+// of the app, in either the PKCS #1 or PKCS #8 format.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("the private key is not PEM encoded")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("the private key is not an RSA key")
+		}
+		return rsaKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %q", block.Type)
+	}
+}
+
+// ReadPrivateKey reads the PEM private key at path.
+func ReadPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("error trying to read the private key %s: %w", path, err)
+	}
+	return key, nil
+}
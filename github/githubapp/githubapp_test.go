@@ -0,0 +1,144 @@
+package githubapp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+// verifyJWT checks the signature of the JWT, and returns its claims.
+func verifyJWT(t *testing.T, jwt string, key *rsa.PublicKey) map[string]interface{} {
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature))
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &claims))
+	return claims
+}
+
+func TestSignJWT(t *testing.T) {
+	key := newKey(t)
+	now := time.Unix(1_600_000_000, 0)
+
+	jwt, err := SignJWT(42, key, now)
+	require.NoError(t, err)
+	claims := verifyJWT(t, jwt, &key.PublicKey)
+	require.Equal(t, "42", claims["iss"])
+	require.Equal(t, float64(now.Add(-time.Minute).Unix()), claims["iat"])
+	require.Equal(t, float64(now.Add(9*time.Minute).Unix()), claims["exp"])
+}
+
+func TestTokenSource(t *testing.T) {
+	key := newKey(t)
+	now := time.Now()
+	lifetime := time.Hour
+	issued := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/v3/app/installations/7/access_tokens", r.URL.Path)
+		claims := verifyJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), &key.PublicKey)
+		require.Equal(t, "42", claims["iss"])
+
+		issued++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "token-%d", "expires_at": %q}`, issued, now.Add(lifetime).Format(time.RFC3339))
+	}))
+	t.Cleanup(server.Close)
+
+	newSource := func() installationTokens {
+		return installationTokens{Config{
+			Client:         server.Client(),
+			BaseURL:        server.URL + "/api/v3/",
+			AppID:          42,
+			InstallationID: 7,
+			Key:            key,
+			now:            time.Now,
+		}}
+	}
+
+	token, err := newSource().Token()
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token.AccessToken)
+	require.WithinDuration(t, now.Add(lifetime-RefreshBefore), token.Expiry, time.Second)
+
+	// Tokens are reused while valid.
+	ts, err := NewTokenSource(newSource().Config)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		token, err = ts.Token()
+		require.NoError(t, err)
+		require.Equal(t, "token-2", token.AccessToken)
+	}
+
+	// And replaced once about to expire.
+	lifetime = RefreshBefore - time.Second
+	ts, err = NewTokenSource(newSource().Config)
+	require.NoError(t, err)
+	token, err = ts.Token()
+	require.NoError(t, err)
+	require.Equal(t, "token-3", token.AccessToken)
+	token, err = ts.Token()
+	require.NoError(t, err)
+	require.Equal(t, "token-4", token.AccessToken)
+}
+
+func TestTokenSourceErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "A JSON web token could not be decoded"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewTokenSource(Config{AppID: 42})
+	require.EqualError(t, err, "both the app ID and the installation ID are needed")
+
+	ts, err := NewTokenSource(Config{BaseURL: server.URL, AppID: 42, InstallationID: 7, Key: newKey(t)})
+	require.NoError(t, err)
+	_, err = ts.Token()
+	require.EqualError(t, err, "error trying to get an installation token: 401 Unauthorized A JSON web token could not be decoded")
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := newKey(t)
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	parsed, err := ParsePrivateKey(pkcs1)
+	require.NoError(t, err)
+	require.True(t, key.Equal(parsed))
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	parsed, err = ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	require.True(t, key.Equal(parsed))
+
+	_, err = ParsePrivateKey([]byte("not a key"))
+	require.EqualError(t, err, "the private key is not PEM encoded")
+}
@@ -0,0 +1,91 @@
+package githubtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// Licenses are the licenses of the generated repositories.
+var Licenses = []github.License{
+	{Key: "mit", Name: "MIT License", SpdxID: "MIT"},
+	{Key: "apache-2.0", Name: "Apache License 2.0", SpdxID: "Apache-2.0"},
+	{Key: "gpl-3.0", Name: "GNU General Public License v3.0", SpdxID: "GPL-3.0"},
+	{Key: "bsd-3-clause", Name: "BSD 3-Clause \"New\" or \"Revised\" License", SpdxID: "BSD-3-Clause"},
+	{Key: "mpl-2.0", Name: "Mozilla Public License 2.0", SpdxID: "MPL-2.0"},
+	{Key: "unlicense", Name: "The Unlicense", SpdxID: "Unlicense"},
+	{Key: "other", Name: "Other", SpdxID: "NOASSERTION"},
+}
+
+// Dataset describes the repositories generated by Generate.
+type Dataset struct {
+	// Seed makes the generated repositories reproducible.
+	Seed int64
+	// Count is the number of repositories.
+	Count int
+	// FirstID is the ID of the first repository. The following IDs
+	// are spaced by up to MaxGap, as IDs of deleted or private
+	// repositories are missing from the real listing.
+	FirstID int
+	MaxGap  int
+	// Owners is the number of distinct owners, a third of which are
+	// organizations.
+	Owners int
+	// Start is the creation time of the first repository, the
+	// following ones are created a minute apart per ID.
+	Start time.Time
+}
+
+// Generate returns repositories with star counts spread over all the
+// buckets, a mix of licenses, including none, and of owner types.
+func Generate(d Dataset) []github.Repos {
+	if d.FirstID < 1 {
+		d.FirstID = 1
+	}
+	if d.MaxGap < 1 {
+		d.MaxGap = 1
+	}
+	if d.Owners < 1 {
+		d.Owners = 1
+	}
+	if d.Start.IsZero() {
+		d.Start = time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	rnd := rand.New(rand.NewSource(d.Seed))
+	repos := make([]github.Repos, 0, d.Count)
+	id := d.FirstID
+	for i := 0; i < d.Count; i++ {
+		ownerID := rnd.Intn(d.Owners) + 1
+		owner := github.Owner{ID: ownerID, Login: fmt.Sprintf("user%d", ownerID), Type: "User"}
+		if ownerID%3 == 0 {
+			owner.Login, owner.Type = fmt.Sprintf("org%d", ownerID), "Organization"
+		}
+
+		// Star counts are heavy tailed: most repositories have a
+		// handful, few have thousands.
+		stars := int(math.Exp(rnd.Float64()*rnd.Float64()*11)) - 1
+
+		var license github.License
+		if n := rnd.Intn(len(Licenses) * 2); n < len(Licenses) {
+			license = Licenses[n]
+		}
+
+		name := fmt.Sprintf("repo%d", id)
+		repos = append(repos, github.Repos{
+			ID:              id,
+			NodeID:          fmt.Sprintf("MDEwOlJlcG9zaXRvcnk%d", id),
+			Name:            name,
+			FullName:        owner.Login + "/" + name,
+			Owner:           owner,
+			StargazersCount: stars,
+			License:         license,
+			CreatedAt:       d.Start.Add(time.Duration(id-d.FirstID) * time.Minute),
+		})
+		id += rnd.Intn(d.MaxGap) + 1
+	}
+	return repos
+}
@@ -0,0 +1,348 @@
+// Package githubtest provides an in-memory fake of the parts of the GitHub
+// REST API used by ghinfo, for tests.
+//
+// The fake serves a fixed set of repositories on:
+//
+//	GET /repositories?since=             the public listing, with Link headers
+//	GET /repositories/{id}               a full repository record
+//	GET /repos/{owner}/{name}            a full repository record
+//	GET /repos/{owner}/{name}/license    the license of a repository
+//	GET /search/repositories?q=          the search, for a subset of qualifiers
+//
+// Every response carries the rate limit headers, and requests are refused
+// once the rate limit is used up. The API can be served under a path
+// prefix, and without a rate limit, as on a GitHub Enterprise Server.
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// DefaultPageSize is the number of repositories per page of the listing,
+// as with the real API.
+const DefaultPageSize = 100
+
+// DefaultRateLimit is the number of requests allowed per rate limit
+// window, as for an authenticated user of the real API.
+const DefaultRateLimit = 5000
+
+// Server is a fake GitHub API. Its settings can be changed until the
+// first request is made.
+type Server struct {
+	*httptest.Server
+
+	// PageSize is the number of repositories per page of the listing
+	// and of the search results.
+	PageSize int
+	// RateLimit is the number of requests allowed before the rate
+	// limit is used up. When 0, there is no rate limit and no rate limit
+	// headers, as on a GitHub Enterprise Server with rate limiting
+	// disabled.
+	RateLimit int
+	// PathPrefix is the path the API is served under, e.g. `/api/v3` as
+	// on a GitHub Enterprise Server.
+	PathPrefix string
+
+	repos      []github.Repos // by ascending ID
+	byID       map[int]int
+	byFullName map[string]int
+
+	mu       sync.Mutex
+	used     int
+	requests map[string]int
+	failures map[string][]int
+}
+
+// NewServer starts a fake serving the given repositories. The caller
+// must call Close when done with it.
+func NewServer(repos []github.Repos) *Server {
+	s := &Server{
+		PageSize:   DefaultPageSize,
+		RateLimit:  DefaultRateLimit,
+		repos:      append([]github.Repos(nil), repos...),
+		byID:       make(map[int]int),
+		byFullName: make(map[string]int),
+		requests:   make(map[string]int),
+		failures:   make(map[string][]int),
+	}
+
+	sort.Slice(s.repos, func(i, j int) bool { return s.repos[i].ID < s.repos[j].ID })
+	for i := range s.repos {
+		r := &s.repos[i]
+		if r.FullName == "" {
+			r.FullName = r.Owner.Login + "/" + r.Name
+		}
+		s.byID[r.ID] = i
+		s.byFullName[strings.ToLower(r.FullName)] = i
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Github returns a client of the fake.
+func (s *Server) Github() *github.Github {
+	gh, err := github.New(s.Client(), s.apiURL(), "githubtest")
+	if err != nil {
+		// The URL of an httptest server always parses.
+		panic(err)
+	}
+	return gh
+}
+
+// apiURL returns the URL the API is served at.
+func (s *Server) apiURL() string {
+	return s.URL + s.PathPrefix
+}
+
+// Repos returns the served repositories, by ascending ID.
+func (s *Server) Repos() []github.Repos {
+	return append([]github.Repos(nil), s.repos...)
+}
+
+// FailNext makes the next requests to path, e.g. `/repositories`, fail with
+// the given statuses, one request per status.
+func (s *Server) FailNext(path string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[path] = append(s.failures[path], statuses...)
+}
+
+// Requests returns the number of requests made to path, including the
+// failed ones.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// TotalRequests returns the number of requests made to the fake.
+func (s *Server) TotalRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := 0
+	for _, n := range s.requests {
+		total += n
+	}
+	return total
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, s.PathPrefix+"/") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	r.URL.Path = strings.TrimPrefix(r.URL.Path, s.PathPrefix)
+	path := r.URL.Path
+
+	s.mu.Lock()
+	s.requests[path]++
+	var failure int
+	if statuses := s.failures[path]; len(statuses) > 0 {
+		failure, s.failures[path] = statuses[0], statuses[1:]
+	}
+	unlimited := s.RateLimit == 0
+	limited := !unlimited && s.used >= s.RateLimit
+	if !limited {
+		s.used++
+	}
+	remaining := s.RateLimit - s.used
+	s.mu.Unlock()
+
+	if !unlimited {
+		h := w.Header()
+		h.Set("X-RateLimit-Limit", strconv.Itoa(s.RateLimit))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		h.Set("X-RateLimit-Used", strconv.Itoa(s.RateLimit-remaining))
+		h.Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		h.Set("X-RateLimit-Resource", "core")
+		if strings.HasPrefix(path, "/search/") {
+			h.Set("X-RateLimit-Resource", "search")
+		}
+	}
+
+	switch {
+	case r.Method != http.MethodGet:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	case limited:
+		writeError(w, http.StatusForbidden, "API rate limit exceeded")
+	case failure != 0:
+		writeError(w, failure, http.StatusText(failure))
+	case path == "/repositories":
+		s.serveListing(w, r)
+	case strings.HasPrefix(path, "/repositories/"):
+		s.serveRepoByID(w, strings.TrimPrefix(path, "/repositories/"))
+	case strings.HasPrefix(path, "/repos/"):
+		s.serveRepo(w, strings.Split(strings.TrimPrefix(path, "/repos/"), "/"))
+	case path == "/search/repositories":
+		s.serveSearch(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) serveListing(w http.ResponseWriter, r *http.Request) {
+	since := 0
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = strconv.Atoi(v); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "Invalid since parameter")
+			return
+		}
+	}
+
+	first := sort.Search(len(s.repos), func(i int) bool { return s.repos[i].ID > since })
+	last := first + s.PageSize
+	if last > len(s.repos) {
+		last = len(s.repos)
+	}
+
+	page := make([]github.Repos, 0, last-first)
+	for _, repo := range s.repos[first:last] {
+		page = append(page, listed(repo))
+	}
+
+	links := []string{fmt.Sprintf(`<%s/repositories?since=0>; rel="first"`, s.apiURL())}
+	if last < len(s.repos) {
+		links = append([]string{fmt.Sprintf(`<%s/repositories?since=%d>; rel="next"`, s.apiURL(), s.repos[last-1].ID)}, links...)
+	}
+	w.Header().Set("Link", strings.Join(links, ", "))
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) serveRepoByID(w http.ResponseWriter, v string) {
+	id, err := strconv.Atoi(v)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	i, ok := s.byID[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.repos[i])
+}
+
+func (s *Server) serveRepo(w http.ResponseWriter, segments []string) {
+	if len(segments) < 2 || len(segments) > 3 || (len(segments) == 3 && segments[2] != "license") {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	i, ok := s.byFullName[strings.ToLower(segments[0]+"/"+segments[1])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	repo := s.repos[i]
+
+	if len(segments) == 2 {
+		writeJSON(w, http.StatusOK, repo)
+		return
+	}
+	if repo.License.Key == "" {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Name    string         `json:"name"`
+		Path    string         `json:"path"`
+		License github.License `json:"license"`
+	}{Name: "LICENSE", Path: "LICENSE", License: repo.License})
+}
+
+func (s *Server) serveSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	match, err := parseSearch(q.Get("q"))
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	var results []github.Repos
+	for _, repo := range s.repos {
+		if match(repo) {
+			results = append(results, repo)
+		}
+	}
+
+	desc := q.Get("order") != "asc"
+	switch q.Get("sort") {
+	case "stars":
+		sort.SliceStable(results, func(i, j int) bool {
+			if desc {
+				return results[i].StargazersCount > results[j].StargazersCount
+			}
+			return results[i].StargazersCount < results[j].StargazersCount
+		})
+	case "":
+	default:
+		writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("sorting by %q is not supported by the fake", q.Get("sort")))
+		return
+	}
+
+	perPage := s.PageSize
+	if v, err := strconv.Atoi(q.Get("per_page")); err == nil && v > 0 && v < perPage {
+		perPage = v
+	}
+	page := 1
+	if v, err := strconv.Atoi(q.Get("page")); err == nil && v > 0 {
+		page = v
+	}
+
+	first := (page - 1) * perPage
+	if first > len(results) {
+		first = len(results)
+	}
+	last := first + perPage
+	if last > len(results) {
+		last = len(results)
+	}
+
+	if last < len(results) {
+		next := *r.URL
+		nq := next.Query()
+		nq.Set("page", strconv.Itoa(page+1))
+		next.RawQuery = nq.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s>; rel="next"`, s.apiURL(), next.String()))
+	}
+
+	items := append([]github.Repos{}, results[first:last]...)
+	writeJSON(w, http.StatusOK, github.Data{TotalCount: len(results), Repos: items})
+}
+
+// listed returns the repository as in the public listing, which leaves
+// out the star count, license and timestamps.
+func listed(repo github.Repos) github.Repos {
+	return github.Repos{
+		ID:       repo.ID,
+		NodeID:   repo.NodeID,
+		Name:     repo.Name,
+		FullName: repo.FullName,
+		Private:  repo.Private,
+		Owner:    repo.Owner,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
@@ -0,0 +1,149 @@
+package githubtest_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/githubtest"
+)
+
+func newServer(t *testing.T) *githubtest.Server {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 7, Count: 30, FirstID: 100, MaxGap: 3, Owners: 6}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, server *githubtest.Server, path string, v interface{}) *http.Response {
+	resp, err := server.Client().Get(server.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	if v != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	}
+	return resp
+}
+
+func TestGenerate(t *testing.T) {
+	d := githubtest.Dataset{Seed: 3, Count: 500, FirstID: 10, MaxGap: 4, Owners: 20}
+	repos := githubtest.Generate(d)
+	require.Equal(t, repos, githubtest.Generate(d), "the same seed generates the same repositories")
+	require.Len(t, repos, d.Count)
+
+	buckets := make(map[string]bool)
+	licensed := 0
+	for i, r := range repos {
+		if i > 0 {
+			require.Greater(t, r.ID, repos[i-1].ID)
+			require.LessOrEqual(t, r.ID-repos[i-1].ID, d.MaxGap)
+		}
+		buckets[github.BucketTier(r.StargazersCount)] = true
+		if r.License.Key != "" {
+			licensed++
+		}
+	}
+	require.Len(t, buckets, len(github.BucketTiers))
+	require.Greater(t, licensed, 0)
+	require.Less(t, licensed, d.Count)
+}
+
+func TestListing(t *testing.T) {
+	server := newServer(t)
+	server.PageSize = 10
+	all := server.Repos()
+
+	var page []github.Repos
+	resp := get(t, server, "/repositories?since=0", &page)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, page, 10)
+	require.Equal(t, all[0].ID, page[0].ID)
+	require.Zero(t, page[0].StargazersCount, "the listing leaves out the star count")
+	require.Contains(t, resp.Header.Get("Link"), `/repositories?since=`+strconv.Itoa(all[9].ID)+`>; rel="next"`)
+
+	resp = get(t, server, "/repositories?since="+strconv.Itoa(all[24].ID), &page)
+	require.Len(t, page, 5)
+	require.NotContains(t, resp.Header.Get("Link"), `rel="next"`)
+}
+
+func TestRepo(t *testing.T) {
+	server := newServer(t)
+	all := server.Repos()
+
+	var licensed, unlicensed github.Repos
+	for _, r := range all {
+		if r.License.Key != "" {
+			licensed = r
+		} else {
+			unlicensed = r
+		}
+	}
+
+	var repo github.Repos
+	resp := get(t, server, "/repos/"+licensed.FullName, &repo)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, licensed, repo)
+
+	resp = get(t, server, "/repositories/"+strconv.Itoa(licensed.ID), &repo)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, licensed, repo)
+
+	var license struct {
+		License github.License `json:"license"`
+	}
+	resp = get(t, server, "/repos/"+licensed.FullName+"/license", &license)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, licensed.License, license.License)
+
+	resp = get(t, server, "/repos/"+unlicensed.FullName+"/license", nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp = get(t, server, "/repos/nobody/nothing", nil)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestSearch(t *testing.T) {
+	server := newServer(t)
+
+	q, err := github.NewSearchQuery().Public().Stars(10, github.Unbounded).Build()
+	require.NoError(t, err)
+
+	var expected []github.Repos
+	for _, r := range server.Repos() {
+		if r.StargazersCount >= 10 {
+			expected = append(expected, r)
+		}
+	}
+	require.NotEmpty(t, expected)
+
+	repos, err := server.Github().QuerySearchRepos(context.Background(), github.Query{Q: q, MaxID: 1 << 30})
+	require.NoError(t, err)
+	require.Len(t, repos, len(expected))
+	for i := 1; i < len(repos); i++ {
+		require.LessOrEqual(t, repos[i-1].StargazersCount, repos[i].StargazersCount)
+	}
+
+	resp := get(t, server, "/search/repositories?q=topic:go", nil)
+	require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestRateLimit(t *testing.T) {
+	server := newServer(t)
+	server.RateLimit = 2
+
+	resp := get(t, server, "/repositories", nil)
+	require.Equal(t, "1", resp.Header.Get("X-RateLimit-Remaining"))
+	require.Equal(t, "core", resp.Header.Get("X-RateLimit-Resource"))
+	resp = get(t, server, "/search/repositories?q=is:public", nil)
+	require.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	require.Equal(t, "search", resp.Header.Get("X-RateLimit-Resource"))
+
+	resp = get(t, server, "/repositories", nil)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	require.Equal(t, 3, server.TotalRequests())
+	require.Equal(t, 2, server.Requests("/repositories"))
+}
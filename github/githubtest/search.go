@@ -0,0 +1,178 @@
+package githubtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// parseSearch returns a matcher for the search query. It supports the
+// `stars`, `created`, `license`, `user`, `org`, `is:public` and `fork`
+// qualifiers, and keywords matched against the repository names.
+func parseSearch(q string) (func(github.Repos) bool, error) {
+	if strings.TrimSpace(q) == "" {
+		return nil, fmt.Errorf("the q parameter is required")
+	}
+
+	var matchers []func(github.Repos) bool
+	for _, term := range splitTerms(q) {
+		i := strings.Index(term, ":")
+		if i < 0 {
+			keyword := strings.ToLower(strings.Trim(term, `"`))
+			matchers = append(matchers, func(r github.Repos) bool {
+				return strings.Contains(strings.ToLower(r.Name), keyword)
+			})
+			continue
+		}
+
+		qualifier, value := term[:i], strings.Trim(term[i+1:], `"`)
+		switch qualifier {
+		case "stars":
+			inRange, err := intRange(value)
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, func(r github.Repos) bool { return inRange(r.StargazersCount) })
+		case "created":
+			inRange, err := dateRange(value)
+			if err != nil {
+				return nil, err
+			}
+			matchers = append(matchers, func(r github.Repos) bool { return inRange(r.CreatedAt) })
+		case "license":
+			matchers = append(matchers, func(r github.Repos) bool { return strings.EqualFold(r.License.Key, value) })
+		case "user", "org":
+			ownerType := "User"
+			if qualifier == "org" {
+				ownerType = "Organization"
+			}
+			matchers = append(matchers, func(r github.Repos) bool {
+				return strings.EqualFold(r.Owner.Login, value) && r.Owner.Type == ownerType
+			})
+		case "is":
+			if value != "public" {
+				return nil, fmt.Errorf("is:%s is not supported by the fake", value)
+			}
+			matchers = append(matchers, func(r github.Repos) bool { return !r.Private })
+		case "fork":
+			// The fake has no forks.
+			if value == "only" {
+				matchers = append(matchers, func(github.Repos) bool { return false })
+			}
+		default:
+			return nil, fmt.Errorf("the %s qualifier is not supported by the fake", qualifier)
+		}
+	}
+
+	return func(r github.Repos) bool {
+		for _, m := range matchers {
+			if !m(r) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// splitTerms splits the query on spaces, except inside quotes.
+func splitTerms(q string) []string {
+	var terms []string
+	var b strings.Builder
+	quoted := false
+	for _, c := range q {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			b.WriteRune(c)
+		case c == ' ' && !quoted:
+			if b.Len() > 0 {
+				terms = append(terms, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+	if b.Len() > 0 {
+		terms = append(terms, b.String())
+	}
+	return terms
+}
+
+// intRange parses `n`, `a..b`, `>=n`, `>n`, `<=n` and `<n`.
+func intRange(v string) (func(int) bool, error) {
+	atoi := func(s string) (int, error) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid range %q", v)
+		}
+		return n, nil
+	}
+
+	switch {
+	case strings.Contains(v, ".."):
+		ends := strings.SplitN(v, "..", 2)
+		lo, err := atoi(ends[0])
+		if err != nil {
+			return nil, err
+		}
+		hi, err := atoi(ends[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(n int) bool { return n >= lo && n <= hi }, nil
+	case strings.HasPrefix(v, ">="):
+		lo, err := atoi(v[2:])
+		return func(n int) bool { return n >= lo }, err
+	case strings.HasPrefix(v, "<="):
+		hi, err := atoi(v[2:])
+		return func(n int) bool { return n <= hi }, err
+	case strings.HasPrefix(v, ">"):
+		lo, err := atoi(v[1:])
+		return func(n int) bool { return n > lo }, err
+	case strings.HasPrefix(v, "<"):
+		hi, err := atoi(v[1:])
+		return func(n int) bool { return n < hi }, err
+	default:
+		exact, err := atoi(v)
+		return func(n int) bool { return n == exact }, err
+	}
+}
+
+// dateRange parses `a..b`, `>=a` and `<=b`, with dates formatted as
+// YYYY-MM-DD.
+func dateRange(v string) (func(time.Time) bool, error) {
+	parse := func(s string) (time.Time, error) {
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date range %q", v)
+		}
+		return t, nil
+	}
+	day := 24 * time.Hour
+
+	switch {
+	case strings.Contains(v, ".."):
+		ends := strings.SplitN(v, "..", 2)
+		from, err := parse(ends[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parse(ends[1])
+		if err != nil {
+			return nil, err
+		}
+		return func(t time.Time) bool { return !t.Before(from) && t.Before(to.Add(day)) }, nil
+	case strings.HasPrefix(v, ">="):
+		from, err := parse(v[2:])
+		return func(t time.Time) bool { return !t.Before(from) }, err
+	case strings.HasPrefix(v, "<="):
+		to, err := parse(v[2:])
+		return func(t time.Time) bool { return t.Before(to.Add(day)) }, err
+	default:
+		return nil, fmt.Errorf("the date range %q is not supported by the fake", v)
+	}
+}
@@ -0,0 +1,112 @@
+package github
+
+import (
+	"context"
+	"fmt"
+)
+
+// RepoIterator lists the public repositories of a query one page at a
+// time, so that only the current page is held in memory:
+//
+//	it := gh.IterateRepos(ctx, query)
+//	for it.Next() {
+//		repo := it.Repo()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type RepoIterator struct {
+	gh    *Github
+	ctx   context.Context
+	maxID int
+
+	next  string
+	page  []Repos
+	i     int
+	pages int
+	repo  Repos
+	done  bool
+	err   error
+}
+
+// IterateRepos returns an iterator over the public repositories with an
+// ID greater than `query.Since`, up to and including `query.MaxID`, in
+// ascending ID order. No request is made until Next is called.
+func (gh *Github) IterateRepos(ctx context.Context, query Query) *RepoIterator {
+	githubURL := gh.endpoint("/repositories")
+
+	q := githubURL.Query()
+	q.Set("since", fmt.Sprint(query.Since))
+	githubURL.RawQuery = q.Encode()
+
+	return &RepoIterator{
+		gh:    gh,
+		ctx:   ctx,
+		maxID: query.MaxID,
+		next:  githubURL.String(),
+	}
+}
+
+// Next advances to the next repository, fetching the next page when the
+// current one is done. It returns false at the end of the range, or on
+// error, which Err returns.
+func (it *RepoIterator) Next() bool {
+	for !it.done && it.i == len(it.page) {
+		it.fetch()
+	}
+	if it.done {
+		return false
+	}
+
+	repo := it.page[it.i]
+	it.i++
+	if repo.ID > it.maxID {
+		it.done = true
+		return false
+	}
+	if repo.ID == it.maxID {
+		// The page holds nothing more in range, don't fetch the next.
+		it.page, it.i, it.next = nil, 0, ""
+	}
+
+	it.repo = repo
+	return true
+}
+
+func (it *RepoIterator) fetch() {
+	if it.next == "" {
+		it.done = true
+		return
+	}
+
+	var repos []Repos
+	resp, err := it.gh.do(it.ctx, it.next, &repos)
+	if err != nil {
+		it.err, it.done = err, true
+		return
+	}
+	it.pages++
+	if len(repos) == 0 {
+		it.done = true
+		return
+	}
+
+	it.page, it.i = repos, 0
+	it.next, _ = findNextPage(resp)
+}
+
+// Repo returns the current repository.
+func (it *RepoIterator) Repo() Repos {
+	return it.repo
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *RepoIterator) Err() error {
+	return it.err
+}
+
+// Pages returns the number of pages fetched so far.
+func (it *RepoIterator) Pages() int {
+	return it.pages
+}
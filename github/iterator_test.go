@@ -0,0 +1,92 @@
+package github_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/githubtest"
+)
+
+func TestIterateRepos(t *testing.T) {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 4, Count: 25, FirstID: 100}))
+	t.Cleanup(server.Close)
+	server.PageSize = 5
+	gh := server.Github()
+
+	testCases := []struct {
+		name          string
+		query         github.Query
+		expectedIDs   []int
+		expectedPages int
+	}{
+		{
+			name:          "range within a page",
+			query:         github.Query{Since: 100, MaxID: 103},
+			expectedIDs:   []int{101, 102, 103},
+			expectedPages: 1,
+		},
+		{
+			name:          "range ending on the last repository of a page",
+			query:         github.Query{Since: 99, MaxID: 109},
+			expectedIDs:   []int{100, 101, 102, 103, 104, 105, 106, 107, 108, 109},
+			expectedPages: 2,
+		},
+		{
+			name:          "range across pages",
+			query:         github.Query{Since: 102, MaxID: 111},
+			expectedIDs:   []int{103, 104, 105, 106, 107, 108, 109, 110, 111},
+			expectedPages: 2,
+		},
+		{
+			name:          "range past the newest repository",
+			query:         github.Query{Since: 118, MaxID: 500},
+			expectedIDs:   []int{119, 120, 121, 122, 123, 124},
+			expectedPages: 2,
+		},
+		{
+			name:          "range before the first repository",
+			query:         github.Query{Since: 10, MaxID: 50},
+			expectedPages: 1,
+		},
+		{
+			name:          "empty range",
+			query:         github.Query{Since: 110, MaxID: 110},
+			expectedPages: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			it := gh.IterateRepos(context.Background(), tc.query)
+
+			var ids []int
+			for it.Next() {
+				ids = append(ids, it.Repo().ID)
+			}
+			require.NoError(t, it.Err())
+			require.Equal(t, tc.expectedIDs, ids)
+			require.Equal(t, tc.expectedPages, it.Pages())
+			require.False(t, it.Next(), "the iterator stays done")
+		})
+	}
+}
+
+// TestIterateReposError asserts that the repositories listed before a
+// failed page are still yielded, and the failure reported.
+func TestIterateReposError(t *testing.T) {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 4, Count: 25, FirstID: 100}))
+	t.Cleanup(server.Close)
+	server.PageSize = 5
+	server.RateLimit = 2
+
+	it := server.Github().IterateRepos(context.Background(), github.Query{Since: 99, MaxID: 200})
+	n := 0
+	for it.Next() {
+		n++
+	}
+	require.Equal(t, 10, n)
+	require.EqualError(t, it.Err(), "something went wrong with the request: 403 Forbidden")
+}
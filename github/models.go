@@ -1,5 +1,7 @@
 package github
 
+import "time"
+
 // Query is used to handle parameters for querying and filtering the GH API.
 //
 // Note: fo the public repositories endpoint, the `page` and `per_page` parameters are not
@@ -19,8 +21,13 @@ type Query struct {
 	// MaxID is the cut off repo ID we want to to return.
 	MaxID int `url:"max_id,omitempty"`
 
+	// Sort is the field search results are sorted by, e.g. `stars`.
 	Sort string `url:"sort,omitempty"`
 
+	// Order is the direction search results are sorted in, `asc` or `desc`.
+	Order string `url:"order,omitempty"`
+
+	// Q is the search query, see SearchQuery.
 	Q string `url:"q,omitempty"`
 }
 type Data struct {
@@ -32,6 +39,8 @@ type Owner struct {
 	Login string `json:"login"`
 	ID    int    `json:"id"`
 	Name  string `json:"name"`
+	// Type is either `User` or `Organization`.
+	Type string `json:"type"`
 }
 
 type Repos struct {
@@ -43,6 +52,9 @@ type Repos struct {
 	Owner           Owner   `json:"owner"`
 	StargazersCount int     `json:"stargazers_count"`
 	License         License `json:"license"`
+	// CreatedAt is only set on full repository records, it is not
+	// part of the public repositories listing.
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // https://docs.github.com/en/rest/reference/licenses#get-the-license-for-a-repository
@@ -59,21 +71,36 @@ type RepoInfo struct {
 	StarGazersCount int `json:"stargazers_count"`
 }
 
-var bucketTiers = []string{"0..10", "10..100", "100..1000", "1000..5000", "5000..10000", ">=10000"}
+// BucketTiers are the star count buckets, from the least to the most starred.
+var BucketTiers = []string{"0..10", "10..100", "100..1000", "1000..5000", "5000..10000", ">=10000"}
 
-func bucketTier(count int) string {
+// BucketTier returns the star count bucket a repository with `count`
+// stars falls into.
+func BucketTier(count int) string {
 	switch {
 	case count <= 10:
-		return bucketTiers[0]
+		return BucketTiers[0]
 	case count <= 100:
-		return bucketTiers[1]
+		return BucketTiers[1]
 	case count <= 1000:
-		return bucketTiers[2]
+		return BucketTiers[2]
 	case count <= 5000:
-		return bucketTiers[3]
+		return BucketTiers[3]
 	case count <= 10000:
-		return bucketTiers[4]
+		return BucketTiers[4]
 	default:
-		return bucketTiers[5]
+		return BucketTiers[5]
+	}
+}
+
+// BucketTierIndex returns the index of a star count bucket in BucketTiers,
+// to sort buckets from the least to the most starred, or the number of
+// tiers for an unknown bucket, which sorts it last.
+func BucketTierIndex(bucket string) int {
+	for i, tier := range BucketTiers {
+		if tier == bucket {
+			return i
+		}
 	}
+	return len(BucketTiers)
 }
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetries is how many times a request failing with a server error,
+// or without a response, is retried.
+const maxRetries = 2
+
+// retryDelay is the wait before the first retry, doubled for each
+// following one.
+var retryDelay = time.Second
+
+// maxRetryAfter is the longest wait asked by a rate limited response
+// that is honored. Longer ones, e.g. until the primary rate limit is
+// reset, fail the request instead.
+var maxRetryAfter = time.Minute
+
+// The reasons a request is retried for, passed to Observer.ObserveRetry.
+const (
+	RetryServerError = "server_error"
+	RetryRateLimited = "rate_limited"
+	RetryNoResponse  = "no_response"
+)
+
+// retry calls attempt until it succeeds or reports that retrying won't
+// help, at most maxRetries more times, waiting retryDelay before the
+// first retry and twice as long before each following one. Rate limited
+// attempts are retried after the wait their response asks for instead.
+func (gh *Github) retry(ctx context.Context, endpoint string, attempt func() (*http.Response, bool, error)) (*http.Response, error) {
+	delay := retryDelay
+	for n := 0; ; n++ {
+		resp, retry, err := attempt()
+		if err == nil || !retry || n == maxRetries || ctx.Err() != nil {
+			return resp, err
+		}
+
+		wait := delay
+		reason := RetryNoResponse
+		var limited rateLimitedError
+		switch {
+		case errors.As(err, &limited):
+			wait = limited.retryAfter
+			reason = RetryRateLimited
+		case errors.As(err, &serverError{}):
+			reason = RetryServerError
+		}
+
+		gh.observer.ObserveRetry(endpoint, reason)
+		select {
+		case <-time.After(wait):
+			delay *= 2
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable reports whether a request that got no response may succeed
+// when retried. Clients can tell that retrying won't help with a
+// `Retryable() bool` method on their errors, e.g. when a response is
+// missing from a replayed cassette.
+func retryable(err error) bool {
+	var r interface{ Retryable() bool }
+	return !errors.As(err, &r) || r.Retryable()
+}
+
+// statusError reports whether an unsuccessful response may succeed when
+// retried, on server errors and when rate limited with a short enough
+// Retry-After, and returns its error.
+func statusError(resp *http.Response) (bool, error) {
+	err := fmt.Errorf("something went wrong with the request: %s", resp.Status)
+	if wait, ok := retryAfter(resp); ok {
+		return wait <= maxRetryAfter, rateLimitedError{err: err, retryAfter: wait}
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return true, serverError{err}
+	}
+	return false, err
+}
+
+// serverError is a response with a 5xx status.
+type serverError struct {
+	err error
+}
+
+func (e serverError) Error() string { return e.err.Error() }
+
+func (e serverError) Unwrap() error { return e.err }
+
+// rateLimitedError is a response rate limited for retryAfter.
+type rateLimitedError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+func (e rateLimitedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", e.err, e.retryAfter)
+}
+
+func (e rateLimitedError) Unwrap() error { return e.err }
+
+// retryAfter returns how long a rate limited response asks to wait before
+// retrying. Secondary rate limits, and the rate limits configured on a
+// GitHub Enterprise Server, send a 403 or 429 with a Retry-After header.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
@@ -0,0 +1,177 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_doRetries asserts that requests failing with a server error, or rate
+// limited for a short while, are retried, and that the others are not.
+func Test_doRetries(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	testCases := []struct {
+		name             string
+		statuses         []int
+		retryAfter       string
+		expectedStatuses []int
+		expectedRetries  []string
+		expectedError    string
+	}{
+		{
+			name:             "succeeds after a server error",
+			statuses:         []int{http.StatusBadGateway, http.StatusOK},
+			expectedStatuses: []int{http.StatusBadGateway, http.StatusOK},
+			expectedRetries:  []string{RetryServerError},
+		},
+		{
+			name:             "gives up after the last retry",
+			statuses:         []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			expectedRetries:  []string{RetryServerError, RetryServerError},
+			expectedError:    "503 Service Unavailable",
+		},
+		{
+			name:             "does not retry client errors",
+			statuses:         []int{http.StatusNotFound, http.StatusOK},
+			expectedStatuses: []int{http.StatusNotFound},
+			expectedError:    "404 Not Found",
+		},
+		{
+			name:             "waits as asked when rate limited",
+			statuses:         []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:       "0",
+			expectedStatuses: []int{http.StatusTooManyRequests, http.StatusOK},
+			expectedRetries:  []string{RetryRateLimited},
+		},
+		{
+			name:             "does not wait for long rate limits",
+			statuses:         []int{http.StatusForbidden, http.StatusOK},
+			retryAfter:       "3600",
+			expectedStatuses: []int{http.StatusForbidden},
+			expectedError:    "retry after 1h0m0s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "42")
+				if tc.retryAfter != "" {
+					w.Header().Set("Retry-After", tc.retryAfter)
+				}
+				w.WriteHeader(tc.statuses[calls])
+				calls++
+				w.Write([]byte(`{"id": 1}`))
+			}))
+			t.Cleanup(server.Close)
+
+			gh, err := New(nil, server.URL, "test-user-agent")
+			require.NoError(t, err)
+			observer := &recordingObserver{}
+			gh.SetObserver(observer)
+
+			_, err = gh.RepoByID(context.Background(), 1)
+			if tc.expectedError != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expectedStatuses, observer.statuses)
+			require.Equal(t, tc.expectedRetries, observer.retries)
+			require.Len(t, observer.remaining, len(tc.expectedStatuses))
+		})
+	}
+}
+
+// Test_retry asserts that attempts are retried until they succeed or
+// can't, at most maxRetries times, and not once the context is done.
+func Test_retry(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+	gh := &Github{observer: nopObserver{}}
+	failed := errors.New("failed")
+
+	testCases := []struct {
+		name             string
+		retry            []bool
+		expectedAttempts int
+	}{
+		{name: "retries until the last retry", retry: []bool{true, true, true, true}, expectedAttempts: maxRetries + 1},
+		{name: "stops when retrying won't help", retry: []bool{true, false, true}, expectedAttempts: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			_, err := gh.retry(context.Background(), "/repositories", func() (*http.Response, bool, error) {
+				attempts++
+				return nil, tc.retry[attempts-1], failed
+			})
+			require.Equal(t, failed, err)
+			require.Equal(t, tc.expectedAttempts, attempts)
+		})
+	}
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		retryDelay = time.Hour
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		_, err := gh.retry(ctx, "/repositories", func() (*http.Response, bool, error) {
+			attempts++
+			cancel()
+			return nil, true, failed
+		})
+		require.Equal(t, failed, err)
+		require.Equal(t, 1, attempts)
+	})
+}
+
+type unretryableError struct{}
+
+func (unretryableError) Error() string   { return "unretryable" }
+func (unretryableError) Retryable() bool { return false }
+
+func Test_retryable(t *testing.T) {
+	require.True(t, retryable(errors.New("connection reset")))
+	require.False(t, retryable(unretryableError{}))
+	require.False(t, retryable(fmt.Errorf("wrapped: %w", unretryableError{})))
+}
+
+func Test_statusError(t *testing.T) {
+	testCases := []struct {
+		status        int
+		retryAfter    string
+		expectedRetry bool
+		expectedError string
+	}{
+		{status: http.StatusInternalServerError, expectedRetry: true, expectedError: "something went wrong with the request: 500 Internal Server Error"},
+		{status: http.StatusNotFound, expectedError: "something went wrong with the request: 404 Not Found"},
+		{status: http.StatusForbidden, expectedError: "something went wrong with the request: 403 Forbidden"},
+		{status: http.StatusForbidden, retryAfter: "30", expectedRetry: true, expectedError: "something went wrong with the request: 403 Forbidden, retry after 30s"},
+		{status: http.StatusTooManyRequests, retryAfter: "61", expectedError: "something went wrong with the request: 429 Too Many Requests, retry after 1m1s"},
+		{status: http.StatusTooManyRequests, retryAfter: "soon", expectedError: "something went wrong with the request: 429 Too Many Requests"},
+		{status: http.StatusServiceUnavailable, retryAfter: "3600", expectedRetry: true, expectedError: "something went wrong with the request: 503 Service Unavailable"},
+	}
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(tc.status)+" "+tc.retryAfter, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Status: fmt.Sprintf("%d %s", tc.status, http.StatusText(tc.status)), Header: http.Header{}}
+			if tc.retryAfter != "" {
+				resp.Header.Set("Retry-After", tc.retryAfter)
+			}
+			retry, err := statusError(resp)
+			require.Equal(t, tc.expectedRetry, retry)
+			require.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
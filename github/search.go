@@ -0,0 +1,255 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unbounded can be passed as either end of a numeric range to leave
+// that end of the range open.
+const Unbounded = -1
+
+// maxKeywordsLength is the longest free text the search API accepts,
+// not counting qualifiers.
+// https://docs.github.com/en/rest/reference/search#limitations-on-query-length
+const maxKeywordsLength = 256
+
+const searchDateLayout = "2006-01-02"
+
+var (
+	loginRE      = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9]|-[A-Za-z0-9]){0,38}$`)
+	licenseKeyRE = regexp.MustCompile(`^[a-z0-9][a-z0-9.\-]*$`)
+	topicRE      = regexp.MustCompile(`^[a-z0-9][a-z0-9\-]{0,49}$`)
+)
+
+// SearchQuery builds the `q` parameter for the repository search endpoint.
+// Qualifiers are added with the fluent methods and serialised, in the order
+// they were added, by Build.
+//
+// Validation errors do not interrupt the chain: the first one is kept and
+// returned by Build, e.g.
+//
+//	q, err := github.NewSearchQuery().
+//	    Language("go").
+//	    Stars(500, 1000).
+//	    Build()
+type SearchQuery struct {
+	keywords   []string
+	qualifiers []string
+	err        error
+}
+
+// NewSearchQuery returns a query matching the given free text keywords.
+func NewSearchQuery(keywords ...string) *SearchQuery {
+	s := &SearchQuery{}
+	for _, k := range keywords {
+		if strings.TrimSpace(k) == "" {
+			s.setErr(fmt.Errorf("search keyword cannot be empty"))
+			continue
+		}
+		s.keywords = append(s.keywords, quote(k))
+	}
+	return s
+}
+
+// Language restricts the results to repositories written in lang.
+func (s *SearchQuery) Language(lang string) *SearchQuery {
+	if strings.TrimSpace(lang) == "" {
+		return s.setErr(fmt.Errorf("language cannot be empty"))
+	}
+	return s.add("language", quote(lang))
+}
+
+// Stars restricts the results to repositories with a star count between
+// min and max, inclusive. Either end can be Unbounded.
+func (s *SearchQuery) Stars(min, max int) *SearchQuery {
+	r, err := intRange(min, max)
+	if err != nil {
+		return s.setErr(fmt.Errorf("stars: %w", err))
+	}
+	return s.add("stars", r)
+}
+
+// Size restricts the results to repositories with a size, in kilobytes,
+// between min and max, inclusive. Either end can be Unbounded.
+func (s *SearchQuery) Size(min, max int) *SearchQuery {
+	r, err := intRange(min, max)
+	if err != nil {
+		return s.setErr(fmt.Errorf("size: %w", err))
+	}
+	return s.add("size", r)
+}
+
+// Created restricts the results to repositories created between from and
+// to, inclusive. Either end can be the zero time to leave it open.
+func (s *SearchQuery) Created(from, to time.Time) *SearchQuery {
+	r, err := dateRange(from, to)
+	if err != nil {
+		return s.setErr(fmt.Errorf("created: %w", err))
+	}
+	return s.add("created", r)
+}
+
+// Pushed restricts the results to repositories last pushed to between
+// from and to, inclusive. Either end can be the zero time to leave it open.
+func (s *SearchQuery) Pushed(from, to time.Time) *SearchQuery {
+	r, err := dateRange(from, to)
+	if err != nil {
+		return s.setErr(fmt.Errorf("pushed: %w", err))
+	}
+	return s.add("pushed", r)
+}
+
+// Topic restricts the results to repositories classified with topic.
+func (s *SearchQuery) Topic(topic string) *SearchQuery {
+	if !topicRE.MatchString(topic) {
+		return s.setErr(fmt.Errorf("invalid topic %q", topic))
+	}
+	return s.add("topic", topic)
+}
+
+// License restricts the results to repositories using the license with the
+// given key, e.g. `mit` or `apache-2.0`.
+func (s *SearchQuery) License(key string) *SearchQuery {
+	if !licenseKeyRE.MatchString(key) {
+		return s.setErr(fmt.Errorf("invalid license key %q", key))
+	}
+	return s.add("license", key)
+}
+
+// User restricts the results to repositories owned by the user login.
+func (s *SearchQuery) User(login string) *SearchQuery {
+	if !loginRE.MatchString(login) {
+		return s.setErr(fmt.Errorf("invalid user login %q", login))
+	}
+	return s.add("user", login)
+}
+
+// Org restricts the results to repositories owned by the organization login.
+func (s *SearchQuery) Org(login string) *SearchQuery {
+	if !loginRE.MatchString(login) {
+		return s.setErr(fmt.Errorf("invalid organization login %q", login))
+	}
+	return s.add("org", login)
+}
+
+// Archived restricts the results to archived, or non archived, repositories.
+func (s *SearchQuery) Archived(archived bool) *SearchQuery {
+	return s.add("archived", strconv.FormatBool(archived))
+}
+
+// Fork controls whether forks are included in the results.
+func (s *SearchQuery) Fork(mode ForkMode) *SearchQuery {
+	switch mode {
+	case ForkExclude:
+		return s
+	case ForkInclude, ForkOnly:
+		return s.add("fork", string(mode))
+	default:
+		return s.setErr(fmt.Errorf("invalid fork mode %q", mode))
+	}
+}
+
+// Public restricts the results to public repositories.
+func (s *SearchQuery) Public() *SearchQuery {
+	return s.add("is", "public")
+}
+
+// ForkMode is the value of the `fork` search qualifier.
+type ForkMode string
+
+const (
+	// ForkExclude leaves forks out of the results, which is the
+	// API default.
+	ForkExclude ForkMode = ""
+	// ForkInclude includes forks in the results.
+	ForkInclude ForkMode = "true"
+	// ForkOnly returns nothing but forks.
+	ForkOnly ForkMode = "only"
+)
+
+// Build returns the serialised query, or the first validation error
+// found while building it.
+func (s *SearchQuery) Build() (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+
+	keywords := strings.Join(s.keywords, " ")
+	if len(keywords) > maxKeywordsLength {
+		return "", fmt.Errorf("search keywords are %d characters long, the limit is %d", len(keywords), maxKeywordsLength)
+	}
+
+	terms := append(append([]string{}, s.keywords...), s.qualifiers...)
+	if len(terms) == 0 {
+		return "", fmt.Errorf("search query cannot be empty")
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// String returns the serialised query, ignoring validation errors.
+func (s *SearchQuery) String() string {
+	return strings.Join(append(append([]string{}, s.keywords...), s.qualifiers...), " ")
+}
+
+func (s *SearchQuery) add(qualifier, value string) *SearchQuery {
+	s.qualifiers = append(s.qualifiers, qualifier+":"+value)
+	return s
+}
+
+func (s *SearchQuery) setErr(err error) *SearchQuery {
+	if s.err == nil {
+		s.err = err
+	}
+	return s
+}
+
+// quote wraps values that contain whitespace or search syntax characters
+// in double quotes, escaping any quotes and backslashes inside them.
+func quote(v string) string {
+	if !strings.ContainsAny(v, " \t\n\"\\:()") {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(v) + `"`
+}
+
+func intRange(min, max int) (string, error) {
+	if min < Unbounded || max < Unbounded {
+		return "", fmt.Errorf("range bounds cannot be negative (%d..%d)", min, max)
+	}
+
+	switch {
+	case min == Unbounded && max == Unbounded:
+		return "", fmt.Errorf("at least one range bound must be set")
+	case min == Unbounded:
+		return "<=" + strconv.Itoa(max), nil
+	case max == Unbounded:
+		return ">=" + strconv.Itoa(min), nil
+	case min > max:
+		return "", fmt.Errorf("the minimum (%d) cannot be larger than the maximum (%d)", min, max)
+	case min == max:
+		return strconv.Itoa(min), nil
+	default:
+		return strconv.Itoa(min) + ".." + strconv.Itoa(max), nil
+	}
+}
+
+func dateRange(from, to time.Time) (string, error) {
+	switch {
+	case from.IsZero() && to.IsZero():
+		return "", fmt.Errorf("at least one date bound must be set")
+	case from.IsZero():
+		return "<=" + to.Format(searchDateLayout), nil
+	case to.IsZero():
+		return ">=" + from.Format(searchDateLayout), nil
+	case from.After(to):
+		return "", fmt.Errorf("the start date (%s) cannot be after the end date (%s)",
+			from.Format(searchDateLayout), to.Format(searchDateLayout))
+	default:
+		return from.Format(searchDateLayout) + ".." + to.Format(searchDateLayout), nil
+	}
+}
@@ -0,0 +1,311 @@
+package github_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// TestSearchQuery asserts that the query builder serialises each
+// qualifier to GitHub's search syntax, and rejects invalid values.
+func TestSearchQuery(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         *github.SearchQuery
+		expected      string
+		expectedError string
+	}{
+		{
+			name:     "keyword",
+			query:    github.NewSearchQuery("ghinfo"),
+			expected: "ghinfo",
+		},
+		{
+			name:     "multiple keywords",
+			query:    github.NewSearchQuery("star", "gazer"),
+			expected: "star gazer",
+		},
+		{
+			name:     "keyword with whitespace is quoted",
+			query:    github.NewSearchQuery("hello world"),
+			expected: `"hello world"`,
+		},
+		{
+			name:     "keyword with quotes is escaped",
+			query:    github.NewSearchQuery(`say "hi"`),
+			expected: `"say \"hi\""`,
+		},
+		{
+			name:     "keyword with backslash is escaped",
+			query:    github.NewSearchQuery(`c:\path`),
+			expected: `"c:\\path"`,
+		},
+		{
+			name:     "keyword with colon is quoted",
+			query:    github.NewSearchQuery("language:go"),
+			expected: `"language:go"`,
+		},
+		{
+			name:          "empty keyword",
+			query:         github.NewSearchQuery(" "),
+			expectedError: "search keyword cannot be empty",
+		},
+		{
+			name:          "keywords too long",
+			query:         github.NewSearchQuery(strings.Repeat("a", 257)),
+			expectedError: "search keywords are 257 characters long, the limit is 256",
+		},
+		{
+			name:          "empty query",
+			query:         github.NewSearchQuery(),
+			expectedError: "search query cannot be empty",
+		},
+		{
+			name:     "language",
+			query:    github.NewSearchQuery().Language("go"),
+			expected: "language:go",
+		},
+		{
+			name:     "language with whitespace",
+			query:    github.NewSearchQuery().Language("Visual Basic"),
+			expected: `language:"Visual Basic"`,
+		},
+		{
+			name:     "language with symbols",
+			query:    github.NewSearchQuery().Language("c++"),
+			expected: "language:c++",
+		},
+		{
+			name:          "empty language",
+			query:         github.NewSearchQuery().Language(""),
+			expectedError: "language cannot be empty",
+		},
+		{
+			name:     "stars range",
+			query:    github.NewSearchQuery().Stars(500, 1000),
+			expected: "stars:500..1000",
+		},
+		{
+			name:     "stars exact",
+			query:    github.NewSearchQuery().Stars(10, 10),
+			expected: "stars:10",
+		},
+		{
+			name:     "stars at least",
+			query:    github.NewSearchQuery().Stars(10, github.Unbounded),
+			expected: "stars:>=10",
+		},
+		{
+			name:     "stars at most",
+			query:    github.NewSearchQuery().Stars(github.Unbounded, 10),
+			expected: "stars:<=10",
+		},
+		{
+			name:     "stars zero",
+			query:    github.NewSearchQuery().Stars(0, 0),
+			expected: "stars:0",
+		},
+		{
+			name:          "stars unbounded on both ends",
+			query:         github.NewSearchQuery().Stars(github.Unbounded, github.Unbounded),
+			expectedError: "stars: at least one range bound must be set",
+		},
+		{
+			name:          "stars min larger than max",
+			query:         github.NewSearchQuery().Stars(10, 5),
+			expectedError: "stars: the minimum (10) cannot be larger than the maximum (5)",
+		},
+		{
+			name:          "stars negative",
+			query:         github.NewSearchQuery().Stars(-5, 5),
+			expectedError: "stars: range bounds cannot be negative (-5..5)",
+		},
+		{
+			name:     "size range",
+			query:    github.NewSearchQuery().Size(1, 1024),
+			expected: "size:1..1024",
+		},
+		{
+			name:          "size min larger than max",
+			query:         github.NewSearchQuery().Size(2, 1),
+			expectedError: "size: the minimum (2) cannot be larger than the maximum (1)",
+		},
+		{
+			name:     "created range",
+			query:    github.NewSearchQuery().Created(date("2016-03-01"), date("2016-03-31")),
+			expected: "created:2016-03-01..2016-03-31",
+		},
+		{
+			name:     "created after",
+			query:    github.NewSearchQuery().Created(date("2016-03-01"), time.Time{}),
+			expected: "created:>=2016-03-01",
+		},
+		{
+			name:     "created before",
+			query:    github.NewSearchQuery().Created(time.Time{}, date("2016-03-31")),
+			expected: "created:<=2016-03-31",
+		},
+		{
+			name:          "created unbounded on both ends",
+			query:         github.NewSearchQuery().Created(time.Time{}, time.Time{}),
+			expectedError: "created: at least one date bound must be set",
+		},
+		{
+			name:          "created start after end",
+			query:         github.NewSearchQuery().Created(date("2016-04-01"), date("2016-03-01")),
+			expectedError: "created: the start date (2016-04-01) cannot be after the end date (2016-03-01)",
+		},
+		{
+			name:     "pushed range",
+			query:    github.NewSearchQuery().Pushed(date("2021-01-01"), date("2021-06-30")),
+			expected: "pushed:2021-01-01..2021-06-30",
+		},
+		{
+			name:     "topic",
+			query:    github.NewSearchQuery().Topic("machine-learning"),
+			expected: "topic:machine-learning",
+		},
+		{
+			name:          "topic with uppercase",
+			query:         github.NewSearchQuery().Topic("Go"),
+			expectedError: `invalid topic "Go"`,
+		},
+		{
+			name:          "topic with whitespace",
+			query:         github.NewSearchQuery().Topic("machine learning"),
+			expectedError: `invalid topic "machine learning"`,
+		},
+		{
+			name:     "license",
+			query:    github.NewSearchQuery().License("apache-2.0"),
+			expected: "license:apache-2.0",
+		},
+		{
+			name:          "license with whitespace",
+			query:         github.NewSearchQuery().License("apache 2"),
+			expectedError: `invalid license key "apache 2"`,
+		},
+		{
+			name:     "user",
+			query:    github.NewSearchQuery().User("carlisia"),
+			expected: "user:carlisia",
+		},
+		{
+			name:     "user with hyphen",
+			query:    github.NewSearchQuery().User("some-user"),
+			expected: "user:some-user",
+		},
+		{
+			name:          "user with leading hyphen",
+			query:         github.NewSearchQuery().User("-user"),
+			expectedError: `invalid user login "-user"`,
+		},
+		{
+			name:          "user with consecutive hyphens",
+			query:         github.NewSearchQuery().User("some--user"),
+			expectedError: `invalid user login "some--user"`,
+		},
+		{
+			name:          "user too long",
+			query:         github.NewSearchQuery().User(strings.Repeat("a", 40)),
+			expectedError: `invalid user login "` + strings.Repeat("a", 40) + `"`,
+		},
+		{
+			name:     "org",
+			query:    github.NewSearchQuery().Org("kubernetes"),
+			expected: "org:kubernetes",
+		},
+		{
+			name:          "org with whitespace",
+			query:         github.NewSearchQuery().Org("my org"),
+			expectedError: `invalid organization login "my org"`,
+		},
+		{
+			name:     "archived",
+			query:    github.NewSearchQuery().Archived(true),
+			expected: "archived:true",
+		},
+		{
+			name:     "not archived",
+			query:    github.NewSearchQuery().Archived(false),
+			expected: "archived:false",
+		},
+		{
+			name:     "include forks",
+			query:    github.NewSearchQuery("x").Fork(github.ForkInclude),
+			expected: "x fork:true",
+		},
+		{
+			name:     "only forks",
+			query:    github.NewSearchQuery("x").Fork(github.ForkOnly),
+			expected: "x fork:only",
+		},
+		{
+			name:     "exclude forks adds no qualifier",
+			query:    github.NewSearchQuery("x").Fork(github.ForkExclude),
+			expected: "x",
+		},
+		{
+			name:          "invalid fork mode",
+			query:         github.NewSearchQuery("x").Fork("sometimes"),
+			expectedError: `invalid fork mode "sometimes"`,
+		},
+		{
+			name:     "public",
+			query:    github.NewSearchQuery().Public(),
+			expected: "is:public",
+		},
+		{
+			name: "qualifiers keep the order they were added in",
+			query: github.NewSearchQuery("cli").
+				Public().
+				Language("go").
+				Stars(500, 1000).
+				Created(date("2016-03-01"), date("2016-03-31")).
+				Topic("github").
+				License("mit").
+				Org("carlisia").
+				Archived(false).
+				Fork(github.ForkInclude),
+			expected: "cli is:public language:go stars:500..1000 created:2016-03-01..2016-03-31 " +
+				"topic:github license:mit org:carlisia archived:false fork:true",
+		},
+		{
+			name: "first error is kept",
+			query: github.NewSearchQuery().
+				Language("go").
+				Stars(10, 1).
+				User("-bad"),
+			expectedError: "stars: the minimum (10) cannot be larger than the maximum (1)",
+		},
+	}
+
+	for _, tc := range testCases {
+		f := func(t *testing.T) {
+			q, err := tc.query.Build()
+			if tc.expectedError != "" {
+				require.EqualError(t, err, tc.expectedError)
+				require.Empty(t, q)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, q)
+			require.Equal(t, tc.expected, tc.query.String())
+		}
+
+		t.Run(tc.name, f)
+	}
+}
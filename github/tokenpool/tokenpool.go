@@ -0,0 +1,273 @@
+// Package tokenpool spreads the requests to the GitHub API over several
+// tokens, so that a crawl can use up the rate limit of each of them.
+//
+// The Pool is a github.HTTPClient. It keeps track of the remaining
+// requests of each token, per rate limit resource, from the rate limit
+// headers of the responses, and sends each request with the token that
+// has the most left. A request rate limited because its token is used up
+// is sent again with the next token, until none is left.
+package tokenpool
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// Pool sends requests with the token that has the most requests left.
+type Pool struct {
+	client github.HTTPClient
+	now    func() time.Time
+
+	mu     sync.Mutex
+	tokens []*token
+}
+
+type token struct {
+	value string
+	// quotas are by rate limit resource, e.g. core or search.
+	quotas map[string]*quota
+}
+
+// quota is what is known of the rate limit of a token for a resource.
+// Tokens are assumed to have requests left until a response tells
+// otherwise.
+type quota struct {
+	known     bool
+	remaining int
+	reset     time.Time
+}
+
+// New returns a pool of the given tokens, sending the requests with
+// client, http.DefaultClient when nil.
+func New(client github.HTTPClient, tokens ...string) (*Pool, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	p := &Pool{client: client, now: time.Now}
+	seen := make(map[string]bool)
+	for _, t := range tokens {
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		p.tokens = append(p.tokens, &token{value: t, quotas: make(map[string]*quota)})
+	}
+	if len(p.tokens) == 0 {
+		return nil, errors.New("the token pool is empty")
+	}
+	return p, nil
+}
+
+// Size returns the number of distinct tokens.
+func (p *Pool) Size() int {
+	return len(p.tokens)
+}
+
+// Remaining returns the requests left for the resource, e.g. `core`,
+// summed over the tokens whose rate limit is known.
+func (p *Pool) Remaining(resource string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	total := 0
+	for _, t := range p.tokens {
+		if q, ok := t.quotas[resource]; ok && q.known {
+			total += q.remaining
+		}
+	}
+	return total
+}
+
+func (p *Pool) Do(req *http.Request) (*http.Response, error) {
+	resource := resourceOf(req.URL.Path)
+	tried := make(map[*token]bool)
+
+	for {
+		t := p.pick(resource, tried)
+		if t == nil {
+			return nil, exhaustedError{tokens: len(p.tokens), reset: p.nextReset(resource)}
+		}
+		tried[t] = true
+
+		authed := req.Clone(req.Context())
+		authed.Header.Set("Authorization", "token "+t.value)
+		resp, err := p.client.Do(authed)
+		if err != nil {
+			return nil, err
+		}
+
+		exhausted := p.update(t, resource, resp)
+		if !exhausted || len(tried) == len(p.tokens) {
+			return resp, nil
+		}
+		// Sent again with the next token, which GET requests, without
+		// a body, allow.
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// pick returns the token with the most requests left for the resource,
+// leaving out the tried ones and the used up ones, and counts the request
+// against it.
+func (p *Pool) pick(resource string, tried map[*token]bool) *token {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var best *token
+	var bestQuota *quota
+	for _, t := range p.tokens {
+		if tried[t] {
+			continue
+		}
+		q, ok := t.quotas[resource]
+		if !ok {
+			q = &quota{}
+			t.quotas[resource] = q
+		}
+		if q.known && !q.reset.IsZero() && now.After(q.reset) {
+			// A new rate limit window started.
+			*q = quota{}
+		}
+		if q.known && q.remaining <= 0 {
+			continue
+		}
+		if best == nil || better(q, bestQuota) {
+			best, bestQuota = t, q
+		}
+	}
+	if bestQuota != nil && bestQuota.known {
+		bestQuota.remaining--
+	}
+	return best
+}
+
+// nextReset returns when the first of the used up tokens gets requests
+// again.
+func (p *Pool) nextReset(resource string) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var next time.Time
+	for _, t := range p.tokens {
+		if q, ok := t.quotas[resource]; ok && q.known && q.remaining <= 0 {
+			if next.IsZero() || q.reset.Before(next) {
+				next = q.reset
+			}
+		}
+	}
+	return next
+}
+
+// exhaustedError is returned when no token has requests left. It is not
+// retried by the GitHub client, since the rate limits are reset much
+// later than the retries.
+type exhaustedError struct {
+	tokens int
+	reset  time.Time
+}
+
+func (e exhaustedError) Error() string {
+	msg := fmt.Sprintf("the rate limit of all %d tokens is used up", e.tokens)
+	if !e.reset.IsZero() {
+		msg += fmt.Sprintf(", until %s", e.reset.Format(time.RFC3339))
+	}
+	return msg
+}
+
+func (e exhaustedError) Retryable() bool { return false }
+
+// better reports whether quota a has more requests left than b, unknown
+// quotas first, so that every token gets used.
+func better(a, b *quota) bool {
+	if !a.known || !b.known {
+		return !a.known && b.known
+	}
+	return a.remaining > b.remaining
+}
+
+// update records the rate limit of the token from the response headers,
+// and reports whether the response was refused because it is used up.
+func (p *Pool) update(t *token, resource string, resp *http.Response) bool {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		// No rate limit, e.g. on a GitHub Enterprise Server with rate
+		// limiting disabled.
+		return false
+	}
+	if r := resp.Header.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	q, ok := t.quotas[resource]
+	if !ok {
+		q = &quota{}
+		t.quotas[resource] = q
+	}
+	q.known, q.remaining = true, remaining
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		q.reset = time.Unix(reset, 0)
+	}
+
+	limited := resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests
+	return limited && remaining == 0
+}
+
+// resourceOf returns the rate limit resource of a request path, before
+// its response tells it.
+func resourceOf(path string) string {
+	if strings.Contains(path, "/search/") {
+		return "search"
+	}
+	return "core"
+}
+
+// ParseTokens returns the tokens of a comma or newline separated list,
+// e.g. the GH_TOKENS variable.
+func ParseTokens(list string) []string {
+	var tokens []string
+	for _, t := range strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// ReadTokens reads the tokens of a file, one per line. Empty lines and
+// lines starting with # are skipped.
+func ReadTokens(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error trying to read the tokens of %s: %w", path, err)
+	}
+	return tokens, nil
+}
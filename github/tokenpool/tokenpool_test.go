@@ -0,0 +1,138 @@
+package tokenpool
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// fakeLimits serves requests while the token they are sent with has
+// requests left, as the GitHub API does.
+type fakeLimits struct {
+	mu        sync.Mutex
+	remaining map[string]int
+	used      map[string]int
+}
+
+func newFakeLimits(t *testing.T, remaining map[string]int) (*fakeLimits, *httptest.Server) {
+	f := &fakeLimits{remaining: remaining, used: make(map[string]int)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "token ")
+		left, ok := f.remaining[token]
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		limited := left == 0
+		if !limited {
+			left--
+			f.remaining[token] = left
+			f.used[token]++
+		}
+
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(left))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+		if limited {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+			return
+		}
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+// TestPool asserts that the requests are spread over the tokens by their
+// remaining requests, and that a used up token is switched from.
+func TestPool(t *testing.T) {
+	f, server := newFakeLimits(t, map[string]int{"a": 10, "b": 4, "c": 1})
+	pool, err := New(server.Client(), "a", "b", "c", "b")
+	require.NoError(t, err)
+	require.Equal(t, 3, pool.Size())
+
+	gh, err := github.New(pool, server.URL, "test-user-agent")
+	require.NoError(t, err)
+
+	for i := 0; i < 15; i++ {
+		_, err := gh.RepoByID(context.Background(), 1)
+		require.NoError(t, err, i)
+	}
+	require.Equal(t, map[string]int{"a": 10, "b": 4, "c": 1}, f.used)
+	require.Equal(t, 0, pool.Remaining("core"))
+
+	_, err = gh.RepoByID(context.Background(), 1)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the rate limit of all 3 tokens is used up, until ")
+}
+
+// TestPoolSwitches asserts that a request refused because its token is
+// used up is sent again with another token.
+func TestPoolSwitches(t *testing.T) {
+	f, server := newFakeLimits(t, map[string]int{"a": 0, "b": 2})
+	pool, err := New(server.Client(), "a", "b")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, server.URL+"/repositories/1", nil)
+	req.RequestURI = ""
+	resp, err := pool.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, map[string]int{"b": 1}, f.used)
+
+	// Once every token is used up, the last response is returned.
+	f.remaining["b"] = 0
+	pool, err = New(server.Client(), "a", "b")
+	require.NoError(t, err)
+	resp, err = pool.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestPoolResets(t *testing.T) {
+	pool, err := New(nil, "a", "b")
+	require.NoError(t, err)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	pool.tokens[0].quotas["core"] = &quota{known: true, remaining: 0, reset: now.Add(time.Minute)}
+	pool.tokens[1].quotas["core"] = &quota{known: true, remaining: 0, reset: now.Add(time.Hour)}
+	require.Nil(t, pool.pick("core", nil))
+	require.Equal(t, now.Add(time.Minute), pool.nextReset("core"))
+
+	// Other resources have their own rate limit.
+	require.NotNil(t, pool.pick("search", nil))
+
+	now = now.Add(2 * time.Minute)
+	require.Equal(t, "a", pool.pick("core", nil).value)
+}
+
+func TestReadTokens(t *testing.T) {
+	require.Equal(t, []string{"a", "b", "c"}, ParseTokens(" a, b\nc,, "))
+
+	path := filepath.Join(t.TempDir(), "tokens")
+	require.NoError(t, ioutil.WriteFile(path, []byte("# crawler tokens\na\n\n  b  \n"), 0o600))
+	tokens, err := ReadTokens(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, tokens)
+
+	_, err = New(nil, "", "")
+	require.EqualError(t, err, "the token pool is empty")
+}
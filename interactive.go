@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/config"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/tui"
+)
+
+// interactiveReports are the reports to choose from in the interactive
+// mode, in the order of the prompts.
+var interactiveReports = []struct {
+	reportType  string
+	description string
+}{
+	{analytics.StarGazersReportType, "the stargazers analytics"},
+	{analytics.LicenseReportType, "the license types analytics"},
+	{analytics.OwnerReportType, "the owners analytics"},
+	{analytics.CrossTabReportType, "the license families by star bucket analytics"},
+}
+
+// interactive runs the report chosen in the terminal UI, or with prompts
+// when the input or the output isn't a terminal. The report is printed
+// once the user quits the UI, to be kept in the terminal.
+func interactive() {
+	if !tui.IsTerminal(os.Stdin, os.Stdout) {
+		prompts()
+		return
+	}
+
+	// The profile is only selected with GHINFO_PROFILE here.
+	var flags apiFlags
+	profile, err := flags.loadProfile()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	api, err := flags.resolve(profile)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	ctx := context.Background()
+	gh, err := newGithub(ctx, api)
+	if err != nil {
+		log.Fatalln("Error trying to initalize the GitHub client:", err)
+	}
+
+	report, err := runTUI(ctx, gh, profile)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	if report == nil {
+		return
+	}
+
+	report.PrintStats()
+	if !profile.Snapshot {
+		return
+	}
+	if err := saveSnapshot(report, profile.Store); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// runTUI runs the report chosen in the terminal UI, showing its progress,
+// then its table until the user quits. The report is nil when the user
+// quits before choosing one.
+func runTUI(ctx context.Context, gh *github.Github, profile config.Profile) (analytics.StatsReport, error) {
+	t, err := tui.Open(os.Stdin, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Close()
+
+	descriptions := make([]string, len(interactiveReports))
+	for i, r := range interactiveReports {
+		descriptions[i] = r.description
+	}
+	setup := tui.NewSetup(descriptions, profile.Since, profile.MaxID)
+	if ok, err := t.Setup(setup); err != nil || !ok {
+		return nil, err
+	}
+
+	report, err := analytics.NewReport(interactiveReports[setup.Report].reportType, analytics.ParamOptions{
+		Asc:         true,
+		Since:       setup.Since,
+		MaxID:       setup.MaxID,
+		Concurrency: profile.Concurrency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid options were selected: %w", err)
+	}
+
+	progress := tui.NewProgress(report.Name(), setup.Since, setup.MaxID)
+	gh.AddObserver(progress)
+	report.(analytics.Watchable).SetProgress(progress.Report)
+	err = t.Watch(ctx, progress, func(ctx context.Context) error {
+		return report.Run(ctx, gh)
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil, errors.New("the report was canceled")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error trying to retrieve the repository list: %w", err)
+	}
+
+	browser, err := tui.NewBrowser(report)
+	if err != nil {
+		return nil, err
+	}
+	return report, t.Browse(browser)
+}
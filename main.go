@@ -8,35 +8,60 @@ import (
 	"strconv"
 
 	"github.com/tcnksm/go-input"
-	"golang.org/x/oauth2"
 
 	"github.com/carlisia/ghinfo/analytics"
 	"github.com/carlisia/ghinfo/github"
 )
 
-const baseURL = "https://api.github.com"
 const userAgent = "https://github.com/carlisia/ghinfo"
+const missingTokenMsg = "\nWhile I have your attention: it seems you don't have a GitHub " +
+	"token configured, so the API is called without one, which only allows 60 requests per hour. " +
+	"Please set the enviroment variable `GH_TOKEN` with your personal token, or log in with the gh CLI, " +
+	"to crawl more than a few repositories.\n\n" +
+	"👋\n\n"
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
+	interactive()
+}
+
+// prompts walks the user through the report options with prompts, when
+// the terminal UI can't be used.
+func prompts() {
 	var input2, reportType string
 	fmt.Print("Welcome! 🌞 Please choose a report kind...\n" +
 		"Type 1 for the stargazers analytics.\n" +
 		"Type 2 for the license types analytics.\n" +
+		"Type 3 for the owners analytics.\n" +
+		"Type 4 for the license families by star bucket analytics.\n" +
 		"$ ")
 	fmt.Scanf("%s", &reportType)
-	if reportType > "2" || reportType < "1" {
+	if reportType > "4" || reportType < "1" {
 		fmt.Printf("Unfortunately %s is not an option. Please try again.\n", reportType)
 		os.Exit(1)
 	}
 	fmt.Printf("Thank you, you have selected %s. We'll get your report started.\n\n", reportType)
 
-	const gitHubToken = "GH_TOKEN"
-	userToken := os.Getenv(gitHubToken)
-	if userToken == "" {
-		fmt.Print("\nWhile I have your attention: it seems you don't have a personal " +
-			"access token configured. Please be sure to set the enviroment variable `GH_Token` " +
-			"with your personal token in order to authenticate and proceed.\n\n" +
-			"👋")
+	// The profile is only selected with GHINFO_PROFILE here.
+	var flags apiFlags
+	profile, err := flags.loadProfile()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	api, err := flags.resolve(profile)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	ctx := context.Background()
+	gh, err := newGithub(ctx, api)
+	if err != nil {
+		fmt.Print(err)
 		os.Exit(1)
 	}
 
@@ -45,12 +70,11 @@ func main() {
 		Reader: os.Stdin,
 	}
 
-	var err error
 	var query, since, maxID string
 
 	query = "What is the Min ID?"
 	since, err = ui.Ask(query, &input.Options{
-		Default:  "65624570",
+		Default:  strconv.Itoa(profile.Since),
 		Required: true,
 	})
 	if err != nil {
@@ -65,7 +89,7 @@ func main() {
 
 	query = "What is the Max ID?"
 	maxID, err = ui.Ask(query, &input.Options{
-		Default:  "65624720",
+		Default:  strconv.Itoa(profile.MaxID),
 		Required: true,
 	})
 	if err != nil {
@@ -79,7 +103,8 @@ func main() {
 	}
 
 	var column string
-	if reportType == analytics.StarGazersReportType {
+	switch reportType {
+	case analytics.StarGazersReportType:
 		fmt.Print("Please choose a column to order by:\n" +
 			"1- bucket\n" +
 			"2- repository\n" +
@@ -90,7 +115,29 @@ func main() {
 			fmt.Printf("Unfortunately %s is not an option. Please try again.\n", column)
 			os.Exit(1)
 		}
-	} else {
+	case analytics.OwnerReportType:
+		fmt.Print("Please choose a column to order by:\n" +
+			"1- owner\n" +
+			"2- owner type\n" +
+			"3- repositories\n" +
+			"4- total stars\n" +
+			"$ ")
+		fmt.Scanf("%s", &column)
+		if column > "4" || column < "1" {
+			fmt.Printf("Unfortunately %s is not an option. Please try again.\n", column)
+			os.Exit(1)
+		}
+	case analytics.CrossTabReportType:
+		fmt.Print("Please choose a column to order by:\n" +
+			"1- bucket\n" +
+			"2- repositories\n" +
+			"$ ")
+		fmt.Scanf("%s", &column)
+		if column > "2" || column < "1" {
+			fmt.Printf("Unfortunately %s is not an option. Please try again.\n", column)
+			os.Exit(1)
+		}
+	default:
 		fmt.Print("Please choose a column to order by::\n" +
 			"1- license type \n" +
 			"2- repository \n" +
@@ -120,10 +167,11 @@ func main() {
 
 	opts := analytics.ParamOptions(
 		analytics.ParamOptions{
-			Column: column,
-			Asc:    !ascBool,
-			Since:  sinceInt,
-			MaxID:  maxIDInt,
+			Column:      column,
+			Asc:         !ascBool,
+			Since:       sinceInt,
+			MaxID:       maxIDInt,
+			Concurrency: profile.Concurrency,
 		},
 	)
 
@@ -133,24 +181,16 @@ func main() {
 		log.Fatalln("Invalid options were selected:", err)
 	}
 
-	tokenSource := oauth2.StaticTokenSource(
-		&oauth2.Token{
-			AccessToken: userToken,
-		},
-	)
-
-	ctx := context.Background()
-	client := oauth2.NewClient(ctx, tokenSource)
-	gh, err := github.New(client, baseURL, userAgent)
-	if err != nil {
-		log.Fatalln("Error trying to initalize the GitHub client:", err)
-	}
-
 	fmt.Printf("\nWe are about to retrive data for your %s ...\n\n", report.Name())
 
 	if err := report.Run(ctx, gh); err != nil {
 		log.Fatalln("Error trying to retrieve the repository list:", err)
 	}
+	if profile.Snapshot {
+		if err := saveSnapshot(report, profile.Store); err != nil {
+			log.Fatalln(err)
+		}
+	}
 
 	fmt.Printf("We have retrieved %d repositories for your report. Would you like to have a print out? Please type `n` to exit.\n"+
 		"$ ", report.Count())
@@ -162,3 +202,12 @@ func main() {
 
 	report.PrintStats()
 }
+
+// newGithub returns an authenticated client of the API of `api`.
+func newGithub(ctx context.Context, api apiSettings) (*github.Github, error) {
+	client, err := authClient(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+	return api.github(client)
+}
@@ -0,0 +1,376 @@
+// Package metrics exports the GitHub API calls and the results of the
+// latest report runs in the Prometheus text format, either served on
+// /metrics or written to a file for the node_exporter textfile collector.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of the API request
+// latency histogram.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Collector collects the metrics. It is a github.Observer, and records
+// the report runs passed to RecordRun.
+type Collector struct {
+	mu sync.Mutex
+
+	requests      map[[2]string]int // by endpoint and status code
+	latencies     map[string]*histogram
+	retries       map[[2]string]int // by endpoint and reason
+	rateLimit     int
+	rateLimitSeen bool
+
+	runs        map[[2]string]int // by report and result
+	lastSuccess map[string]time.Time
+	listed      map[string]int
+	enriched    map[string]int
+	buckets     map[string]int
+	licenses    map[string]int
+	// stages are the seconds spent in each stage of the latest run,
+	// by report and stage.
+	stages       map[[2]string]float64
+	enrichErrors map[string]int
+}
+
+type histogram struct {
+	counts []int // per bucket, not cumulative
+	count  int
+	sum    float64
+}
+
+func (h *histogram) observe(v float64) {
+	h.count++
+	h.sum += v
+	for i, le := range latencyBuckets {
+		if v <= le {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+// New returns a collector without any observation yet.
+func New() *Collector {
+	return &Collector{
+		requests:     make(map[[2]string]int),
+		latencies:    make(map[string]*histogram),
+		retries:      make(map[[2]string]int),
+		runs:         make(map[[2]string]int),
+		lastSuccess:  make(map[string]time.Time),
+		listed:       make(map[string]int),
+		enriched:     make(map[string]int),
+		stages:       make(map[[2]string]float64),
+		enrichErrors: make(map[string]int),
+	}
+}
+
+func (c *Collector) ObserveRequest(endpoint string, status int, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	code := strconv.Itoa(status)
+	if status == 0 {
+		code = "error"
+	}
+	c.requests[[2]string{endpoint, code}]++
+
+	h, ok := c.latencies[endpoint]
+	if !ok {
+		h = &histogram{counts: make([]int, len(latencyBuckets))}
+		c.latencies[endpoint] = h
+	}
+	h.observe(latency.Seconds())
+}
+
+func (c *Collector) ObserveRetry(endpoint, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retries[[2]string{endpoint, reason}]++
+}
+
+func (c *Collector) ObserveRateLimit(remaining int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = remaining
+	c.rateLimitSeen = true
+}
+
+// API returns the totals of the API calls observed.
+func (c *Collector) API() analytics.APIStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := analytics.APIStats{RateLimit: -1}
+	for key, n := range c.requests {
+		stats.Requests += n
+		if status, err := strconv.Atoi(key[1]); err != nil || status >= 400 {
+			stats.Failed += n
+		}
+	}
+	for _, n := range c.retries {
+		stats.Retries += n
+	}
+	if c.rateLimitSeen {
+		stats.RateLimit = c.rateLimit
+	}
+	return stats
+}
+
+// RecordRun records a report run that returned `err`. The repositories
+// per star bucket and per license are taken from the latest successful
+// run of a report keeping its per repository data.
+func (c *Collector) RecordRun(report analytics.StatsReport, err error, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := report.Name()
+	if err != nil {
+		c.runs[[2]string{name, "failure"}]++
+		return
+	}
+	c.runs[[2]string{name, "success"}]++
+	c.lastSuccess[name] = at
+
+	if staged, ok := report.(analytics.Staged); ok {
+		stats := staged.Stats()
+		c.stages[[2]string{name, "list"}] = stats.ListTime.Seconds()
+		c.stages[[2]string{name, "list_blocked"}] = stats.ListBlocked.Seconds()
+		c.stages[[2]string{name, "enrich"}] = stats.EnrichBusy.Seconds()
+		c.stages[[2]string{name, "total"}] = stats.Elapsed.Seconds()
+		c.enrichErrors[name] = stats.EnrichErrors
+	}
+
+	recorder, ok := report.(analytics.Recorder)
+	if !ok {
+		return
+	}
+	results := recorder.Results()
+	c.listed[name] = len(results.Listed)
+	c.enriched[name] = len(results.Enriched)
+
+	c.buckets = make(map[string]int)
+	for _, tier := range github.BucketTiers {
+		c.buckets[tier] = 0
+	}
+	c.licenses = make(map[string]int)
+	for _, repo := range results.Enriched {
+		c.buckets[github.BucketTier(repo.StargazersCount)]++
+		license := repo.License.SpdxID
+		if license == "" {
+			license = "none"
+		}
+		c.licenses[license]++
+	}
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &exposition{w: bufio.NewWriter(w)}
+
+	e.family("ghinfo_github_requests_total", "counter", "GitHub API requests, by endpoint and status code.")
+	for _, key := range sortedPairs(c.requests) {
+		e.sample("ghinfo_github_requests_total", labels("endpoint", key[0], "code", key[1]), float64(c.requests[key]))
+	}
+
+	e.family("ghinfo_github_request_duration_seconds", "histogram", "Latency of the GitHub API requests, by endpoint.")
+	for _, endpoint := range sortedKeys(c.latencies) {
+		h := c.latencies[endpoint]
+		cumulative := 0
+		for i, le := range latencyBuckets {
+			cumulative += h.counts[i]
+			e.sample("ghinfo_github_request_duration_seconds_bucket",
+				labels("endpoint", endpoint, "le", strconv.FormatFloat(le, 'g', -1, 64)), float64(cumulative))
+		}
+		e.sample("ghinfo_github_request_duration_seconds_bucket", labels("endpoint", endpoint, "le", "+Inf"), float64(h.count))
+		e.sample("ghinfo_github_request_duration_seconds_sum", labels("endpoint", endpoint), h.sum)
+		e.sample("ghinfo_github_request_duration_seconds_count", labels("endpoint", endpoint), float64(h.count))
+	}
+
+	e.family("ghinfo_github_retries_total", "counter", "GitHub API requests retried, by endpoint and reason: a server error, a short rate limit wait, or no response.")
+	for _, key := range sortedPairs(c.retries) {
+		e.sample("ghinfo_github_retries_total", labels("endpoint", key[0], "reason", key[1]), float64(c.retries[key]))
+	}
+
+	if c.rateLimitSeen {
+		e.family("ghinfo_github_rate_limit_remaining", "gauge", "GitHub API requests remaining in the current rate limit window.")
+		e.sample("ghinfo_github_rate_limit_remaining", "", float64(c.rateLimit))
+	}
+
+	e.family("ghinfo_report_runs_total", "counter", "Report runs, by report and result.")
+	for _, key := range sortedPairs(c.runs) {
+		e.sample("ghinfo_report_runs_total", labels("report", key[0], "result", key[1]), float64(c.runs[key]))
+	}
+
+	e.family("ghinfo_report_last_success_timestamp_seconds", "gauge", "Time of the latest successful run, by report.")
+	for _, name := range sortedKeys(c.lastSuccess) {
+		e.sample("ghinfo_report_last_success_timestamp_seconds", labels("report", name), float64(c.lastSuccess[name].Unix()))
+	}
+
+	e.family("ghinfo_report_repos_listed", "gauge", "Repositories found in the ID range of the latest run, by report.")
+	for _, name := range sortedKeys(c.listed) {
+		e.sample("ghinfo_report_repos_listed", labels("report", name), float64(c.listed[name]))
+	}
+
+	e.family("ghinfo_report_repos_enriched", "gauge", "Repositories of the latest run whose stars and license could be retrieved, by report.")
+	for _, name := range sortedKeys(c.enriched) {
+		e.sample("ghinfo_report_repos_enriched", labels("report", name), float64(c.enriched[name]))
+	}
+
+	e.family("ghinfo_report_stage_seconds", "gauge", "Seconds spent in each stage of the latest run, by report and stage. The enrich stage is summed over the workers.")
+	for _, key := range sortedPairs(c.stages) {
+		e.sample("ghinfo_report_stage_seconds", labels("report", key[0], "stage", key[1]), c.stages[key])
+	}
+
+	e.family("ghinfo_report_enrich_errors", "gauge", "Repositories of the latest run that could not be enriched, by report.")
+	for _, name := range sortedKeys(c.enrichErrors) {
+		e.sample("ghinfo_report_enrich_errors", labels("report", name), float64(c.enrichErrors[name]))
+	}
+
+	if c.buckets != nil {
+		e.family("ghinfo_repos_by_star_bucket", "gauge", "Repositories of the latest run, by star bucket.")
+		for _, tier := range github.BucketTiers {
+			e.sample("ghinfo_repos_by_star_bucket", labels("bucket", tier), float64(c.buckets[tier]))
+		}
+
+		e.family("ghinfo_repos_by_license", "gauge", "Repositories of the latest run, by SPDX license ID.")
+		for _, license := range sortedKeys(c.licenses) {
+			e.sample("ghinfo_repos_by_license", labels("license", license), float64(c.licenses[license]))
+		}
+	}
+
+	if e.err == nil {
+		e.err = e.w.Flush()
+	}
+	return e.n, e.err
+}
+
+// Handler serves the metrics, e.g. on /metrics.
+func (c *Collector) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.WriteTo(w)
+	})
+}
+
+// WriteFile writes the metrics to path, for the node_exporter textfile
+// collector. The file is replaced at once, so that the collector never
+// reads it half written.
+func (c *Collector) WriteFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := c.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// exposition writes the text format, keeping the first error.
+type exposition struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (e *exposition) printf(format string, args ...interface{}) {
+	if e.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(e.w, format, args...)
+	e.n += int64(n)
+	e.err = err
+}
+
+func (e *exposition) family(name, kind, help string) {
+	e.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (e *exposition) sample(name, labels string, value float64) {
+	e.printf("%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels formats name and value pairs as a label set.
+func labels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, `%s="%s"`, pairs[i], labelEscaper.Replace(pairs[i+1]))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]int:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*histogram:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]time.Time:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedPairs(m interface{}) [][2]string {
+	var keys [][2]string
+	switch m := m.(type) {
+	case map[[2]string]int:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[[2]string]float64:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
@@ -0,0 +1,107 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/metrics"
+)
+
+// fakeReport is a report that was already run.
+type fakeReport struct {
+	results analytics.Results
+	stats   analytics.PipelineStats
+}
+
+func (f fakeReport) Run(context.Context, *github.Github) error { return nil }
+func (f fakeReport) PrintStats()                               {}
+func (f fakeReport) Count() int                                { return len(f.results.Enriched) }
+func (f fakeReport) Name() string                              { return f.results.ReportName }
+func (f fakeReport) Results() analytics.Results                { return f.results }
+func (f fakeReport) Stats() analytics.PipelineStats            { return f.stats }
+
+func TestCollector(t *testing.T) {
+	c := metrics.New()
+	c.ObserveRequest("/repositories", 200, 30*time.Millisecond)
+	c.ObserveRequest("/repos/{owner}/{repo}", 502, 2*time.Second)
+	c.ObserveRetry("/repos/{owner}/{repo}", "server_error")
+	c.ObserveRequest("/repos/{owner}/{repo}", 200, 300*time.Millisecond)
+	c.ObserveRateLimit(4990)
+
+	report := fakeReport{results: analytics.Results{
+		ReportName: "StarGazers Report",
+		Listed:     []github.Repos{{ID: 1}, {ID: 2}, {ID: 3}},
+		Enriched: []github.Repos{
+			{ID: 1, StargazersCount: 5, License: github.License{SpdxID: "MIT"}},
+			{ID: 2, StargazersCount: 20000},
+		},
+	}, stats: analytics.PipelineStats{
+		ListTime:     2 * time.Second,
+		EnrichBusy:   6 * time.Second,
+		Elapsed:      2500 * time.Millisecond,
+		EnrichErrors: 1,
+	}}
+	c.RecordRun(report, errors.New("boom"), time.Unix(100, 0))
+	c.RecordRun(report, nil, time.Unix(200, 0))
+
+	var buf bytes.Buffer
+	_, err := c.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.String()
+
+	for _, line := range []string{
+		"# TYPE ghinfo_github_requests_total counter",
+		`ghinfo_github_requests_total{endpoint="/repos/{owner}/{repo}",code="200"} 1`,
+		`ghinfo_github_requests_total{endpoint="/repos/{owner}/{repo}",code="502"} 1`,
+		`ghinfo_github_request_duration_seconds_bucket{endpoint="/repos/{owner}/{repo}",le="0.5"} 1`,
+		`ghinfo_github_request_duration_seconds_bucket{endpoint="/repos/{owner}/{repo}",le="2.5"} 2`,
+		`ghinfo_github_request_duration_seconds_bucket{endpoint="/repos/{owner}/{repo}",le="+Inf"} 2`,
+		`ghinfo_github_request_duration_seconds_sum{endpoint="/repos/{owner}/{repo}"} 2.3`,
+		`ghinfo_github_retries_total{endpoint="/repos/{owner}/{repo}",reason="server_error"} 1`,
+		"ghinfo_github_rate_limit_remaining 4990",
+		`ghinfo_report_runs_total{report="StarGazers Report",result="failure"} 1`,
+		`ghinfo_report_runs_total{report="StarGazers Report",result="success"} 1`,
+		`ghinfo_report_last_success_timestamp_seconds{report="StarGazers Report"} 200`,
+		`ghinfo_report_repos_listed{report="StarGazers Report"} 3`,
+		`ghinfo_report_repos_enriched{report="StarGazers Report"} 2`,
+		`ghinfo_report_stage_seconds{report="StarGazers Report",stage="enrich"} 6`,
+		`ghinfo_report_stage_seconds{report="StarGazers Report",stage="total"} 2.5`,
+		`ghinfo_report_enrich_errors{report="StarGazers Report"} 1`,
+		`ghinfo_repos_by_star_bucket{bucket="0..10"} 1`,
+		`ghinfo_repos_by_star_bucket{bucket="100..1000"} 0`,
+		`ghinfo_repos_by_star_bucket{bucket=">=10000"} 1`,
+		`ghinfo_repos_by_license{license="MIT"} 1`,
+		`ghinfo_repos_by_license{license="none"} 1`,
+	} {
+		require.Contains(t, out, line+"\n")
+	}
+
+	path := filepath.Join(t.TempDir(), "ghinfo.prom")
+	require.NoError(t, c.WriteFile(path))
+	written, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, out, string(written))
+}
+
+func TestCollectorAPI(t *testing.T) {
+	c := metrics.New()
+	require.Equal(t, analytics.APIStats{RateLimit: -1}, c.API())
+
+	c.ObserveRequest("/repositories", 200, 30*time.Millisecond)
+	c.ObserveRequest("/repos/{owner}/{repo}", 502, 2*time.Second)
+	c.ObserveRetry("/repos/{owner}/{repo}", "server_error")
+	c.ObserveRequest("/repos/{owner}/{repo}", 0, time.Second)
+	c.ObserveRetry("/repos/{owner}/{repo}", "no_response")
+	c.ObserveRequest("/repos/{owner}/{repo}", 200, 300*time.Millisecond)
+	c.ObserveRateLimit(4990)
+	require.Equal(t, analytics.APIStats{Requests: 4, Failed: 2, Retries: 2, RateLimit: 4990}, c.API())
+}
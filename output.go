@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/chart"
+	"github.com/carlisia/ghinfo/sqlite"
+)
+
+const outputUsage = "output format: table, json, csv, markdown, html or svg, optionally followed by =path to write it to a file, or sqlite=path or png=path"
+
+const chartUsage = "draw bar charts of the star buckets and licenses of the repositories, and a histogram of their stars, below the table"
+
+// output is where, and in which format, a report is written to. It is
+// set with `--output format[=path]`; without a path, the report is
+// written to stdout.
+type output struct {
+	format string
+	path   string
+}
+
+func parseOutput(v string) (output, error) {
+	o := output{format: v}
+	if i := strings.Index(v, "="); i >= 0 {
+		o.format, o.path = v[:i], v[i+1:]
+		if o.path == "" {
+			return output{}, fmt.Errorf("missing the file path in --output %s", v)
+		}
+	}
+
+	switch o.format {
+	case "table":
+		if o.path != "" {
+			return output{}, fmt.Errorf("tables can only be printed to stdout")
+		}
+		return o, nil
+	case "json", "csv", "markdown", "html", "svg":
+		return o, nil
+	case "sqlite":
+		if o.path == "" {
+			return output{}, fmt.Errorf("please give the database file to write to, e.g. --output sqlite=ghinfo.db")
+		}
+		return o, nil
+	case "png":
+		if o.path == "" {
+			return output{}, fmt.Errorf("please give the image file to write to, e.g. --output png=charts.png")
+		}
+		return o, nil
+	default:
+		return output{}, fmt.Errorf("unknown output format %q, please use table, json, csv, markdown, html, svg, png or sqlite", o.format)
+	}
+}
+
+// check returns an error when the report can't be written in the
+// output format, so that it fails before the report is run.
+func (o output) check(report analytics.StatsReport) error {
+	switch o.format {
+	case "table":
+		return nil
+	case "sqlite":
+		if _, ok := report.(analytics.Recorder); !ok {
+			return fmt.Errorf("the %s does not keep per repository data to write to a database", report.Name())
+		}
+		return nil
+	case "markdown", "html":
+		if _, ok := report.(analytics.Tabular); !ok {
+			return fmt.Errorf("the %s can't be written as a %s table", report.Name(), o.format)
+		}
+		return nil
+	case "svg", "png":
+		if _, ok := report.(analytics.Charted); !ok {
+			return fmt.Errorf("the %s does not keep per repository data to chart", report.Name())
+		}
+		return nil
+	default:
+		if _, ok := report.(analytics.Exporter); !ok {
+			return fmt.Errorf("the %s can only be printed as a table", report.Name())
+		}
+		return nil
+	}
+}
+
+// write writes the report, with the header of the markdown and html
+// documents, which also titles the charts.
+func (o output) write(ctx context.Context, report analytics.StatsReport, header analytics.Header) error {
+	switch o.format {
+	case "table":
+		report.PrintStats()
+		return nil
+	case "sqlite":
+		results := report.(analytics.Recorder).Results()
+		runID, err := sqlite.Write(ctx, o.path, results, time.Now())
+		if err != nil {
+			return fmt.Errorf("error trying to write the repositories to %s: %w", o.path, err)
+		}
+		fmt.Fprintf(os.Stderr, "%d repositories were written to %s as run %d\n", len(results.Listed), o.path, runID)
+		return nil
+	}
+
+	if o.path == "" {
+		return o.export(os.Stdout, report, header)
+	}
+
+	f, err := os.Create(o.path)
+	if err != nil {
+		return err
+	}
+	if err := o.export(f, report, header); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "The %s was written to %s\n", report.Name(), o.path)
+	return nil
+}
+
+func (o output) export(w io.Writer, report analytics.StatsReport, header analytics.Header) error {
+	switch o.format {
+	case "markdown":
+		return analytics.WriteMarkdown(w, report, header)
+	case "html":
+		return analytics.WriteHTML(w, report, header)
+	case "svg":
+		return chart.WriteSVG(w, chartTitle(report, header), report.(analytics.Charted).Distributions())
+	case "png":
+		return chart.WritePNG(w, chartTitle(report, header), report.(analytics.Charted).Distributions())
+	case "csv":
+		return report.(analytics.Exporter).WriteCSV(w)
+	default:
+		return report.(analytics.Exporter).WriteJSON(w)
+	}
+}
+
+// chartTitle returns the title of the charts of the report.
+func chartTitle(report analytics.StatsReport, header analytics.Header) string {
+	return fmt.Sprintf("%s: repository IDs %d to %d, retrieved at %s",
+		report.Name(), header.Since+1, header.MaxID, header.At.UTC().Format(dateLayout))
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/carlisia/ghinfo/config"
+)
+
+// applyProfile sets the flags that weren't given on the command line to
+// the settings of the profile, by flag name. Unset settings are skipped.
+func applyProfile(fs *flag.FlagSet, settings map[string]string) error {
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	for name, v := range settings {
+		if v == "" || given[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, v); err != nil {
+			return fmt.Errorf("invalid %s in the profile: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// reportSettings returns the settings of the profile applying to the
+// report flags.
+func reportSettings(p config.Profile) map[string]string {
+	itoa := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	return map[string]string{
+		// The range is always set, from the Defaults if not the profile,
+		// and since can be 0.
+		"since":       strconv.Itoa(p.Since),
+		"max-id":      strconv.Itoa(p.MaxID),
+		"sort":        p.Sort,
+		"order":       p.Order,
+		"concurrency": itoa(p.Concurrency),
+		"output":      p.Output,
+		"snapshot":    strconv.FormatBool(p.Snapshot),
+		"store":       p.Store,
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/carlisia/ghinfo/metrics"
+	"github.com/carlisia/ghinfo/server"
+)
+
+// shutdownTimeout is how long in flight requests are given to
+// finish once the server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+func serveCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	var opts server.Options
+	fs.IntVar(&opts.SyncMaxIDs, "sync-max-ids", 50, "longest ID range a report request waits for, longer ranges are run as jobs")
+	fs.IntVar(&opts.MaxJobs, "max-jobs", 2, "number of report jobs that can run at the same time")
+	fs.IntVar(&opts.MaxQueuedJobs, "max-queued-jobs", 10, "number of report jobs that can wait for their turn, further ones are refused")
+	withMetrics := fs.Bool("metrics", true, "serve the Prometheus metrics on /metrics")
+	var client cassetteFlags
+	client.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	profile, err := client.api.loadProfile()
+	if err != nil {
+		return err
+	}
+	opts.Defaults = server.Defaults{
+		Report: profile.Report,
+		Since:  profile.Since,
+		MaxID:  profile.MaxID,
+		Sort:   profile.Sort,
+		Order:  profile.Order,
+	}
+
+	// Canceled on SIGINT or SIGTERM, which stops the running crawls.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	gh, err := client.newGithub(ctx, profile)
+	if err != nil {
+		return fmt.Errorf("error trying to initalize the GitHub client: %w", err)
+	}
+
+	if *withMetrics {
+		opts.Metrics = metrics.New()
+		gh.SetObserver(opts.Metrics)
+	}
+
+	srv := server.New(ctx, gh, opts)
+	httpServer := &http.Server{
+		Addr:    *addr,
+		Handler: srv,
+		// Requests share the server context, so that the reports they
+		// are waiting for are canceled when shutting down.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Printf("Serving the dashboard on http://%s/", *addr)
+		errc <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down, canceling the running reports...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	srv.Wait()
+
+	return nil
+}
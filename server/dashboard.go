@@ -0,0 +1,22 @@
+package server
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// The dashboard is a single page, calling the JSON endpoints to run the
+// reports and drawing their charts.
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+func dashboardHandler() http.Handler {
+	files, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		// The directory is embedded at build time.
+		panic(err)
+	}
+	return http.FileServer(http.FS(files))
+}
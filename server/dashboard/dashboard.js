@@ -0,0 +1,241 @@
+"use strict";
+
+// The columns each report can be sorted by, as accepted by the `sort`
+// parameter of /reports/{name}.
+const columns = {
+  stars: ["bucket", "repos", "stars"],
+  licenses: ["license type", "repos"],
+  owners: ["owner", "type", "repos", "stars"],
+  crosstab: ["bucket", "repos"],
+};
+
+const palette = ["#0969da", "#1a7f37", "#bf8700", "#cf222e", "#8250df", "#bc4c00", "#57606a", "#1b7c83"];
+
+const form = document.getElementById("params");
+const reportSelect = document.getElementById("report");
+const sortSelect = document.getElementById("sort");
+const statusLine = document.getElementById("status");
+
+function setStatus(message, isError) {
+  statusLine.textContent = message;
+  statusLine.className = isError ? "error" : "";
+}
+
+function fillSortColumns() {
+  sortSelect.replaceChildren(...(columns[reportSelect.value] || []).map((column) => new Option(column, column)));
+}
+
+// loadReports lists the reports, and fills the form with the defaults
+// of the server, e.g. the ones of its profile.
+async function loadReports() {
+  const resp = await fetch("reports");
+  const body = await resp.json();
+  const defaults = body.defaults || {};
+  reportSelect.replaceChildren(...body.reports.map((name) => new Option(name, name)));
+  if (body.reports.includes(defaults.report)) {
+    reportSelect.value = defaults.report;
+  }
+  fillSortColumns();
+  document.getElementById("since").value = defaults.since;
+  document.getElementById("max_id").value = defaults.max_id;
+  if ((columns[reportSelect.value] || []).includes(defaults.sort)) {
+    sortSelect.value = defaults.sort;
+  }
+  if (defaults.order) {
+    document.getElementById("order").value = defaults.order;
+  }
+}
+
+const sleep = (ms) => new Promise((resolve) => setTimeout(resolve, ms));
+
+// runReport returns the report document, polling its job when the
+// range was too long for the server to run it while we wait.
+async function runReport(name, params) {
+  let resp = await fetch(`reports/${encodeURIComponent(name)}?${params}`);
+  let body = await resp.json();
+  if (resp.status === 202) {
+    const location = resp.headers.get("Location");
+    while (body.status === "pending" || body.status === "running") {
+      setStatus(`Crawling repositories ${body.since} to ${body.max_id}, job ${body.id} is ${body.status}...`);
+      await sleep(2000);
+      resp = await fetch(location.replace(/^\//, ""));
+      body = await resp.json();
+    }
+    if (body.status !== "done") {
+      throw new Error(body.error || `the job is ${body.status}`);
+    }
+    return body.result;
+  }
+  if (!resp.ok) {
+    throw new Error(body.error || resp.statusText);
+  }
+  return body;
+}
+
+function svgElement(tag, attrs, text) {
+  const el = document.createElementNS("http://www.w3.org/2000/svg", tag);
+  for (const [key, value] of Object.entries(attrs)) {
+    el.setAttribute(key, value);
+  }
+  if (text !== undefined) {
+    el.textContent = text;
+  }
+  return el;
+}
+
+function figure(caption, svg) {
+  const fig = document.createElement("figure");
+  const cap = document.createElement("figcaption");
+  cap.textContent = caption;
+  fig.append(cap, svg);
+  return fig;
+}
+
+// barChart draws horizontal bars, one per item.
+function barChart(caption, items) {
+  const rowHeight = 22;
+  const labelWidth = 140;
+  const width = 420;
+  const max = Math.max(1, ...items.map((item) => item.value));
+  const svg = svgElement("svg", { width, height: items.length * rowHeight + 4 });
+
+  items.forEach((item, i) => {
+    const y = i * rowHeight;
+    const barWidth = ((width - labelWidth - 50) * item.value) / max;
+    svg.append(
+      svgElement("text", { x: labelWidth - 6, y: y + 15, "text-anchor": "end" }, item.label),
+      svgElement("rect", { x: labelWidth, y: y + 3, width: barWidth, height: rowHeight - 6, fill: palette[0] }),
+      svgElement("text", { x: labelWidth + barWidth + 4, y: y + 15 }, item.value.toLocaleString())
+    );
+  });
+  return figure(caption, svg);
+}
+
+// pieChart draws the share of each item, with a legend.
+function pieChart(caption, items) {
+  const radius = 90;
+  const total = items.reduce((sum, item) => sum + item.value, 0);
+  const svg = svgElement("svg", { width: 420, height: Math.max(2 * radius + 10, items.length * 20) });
+
+  let angle = -Math.PI / 2;
+  items.forEach((item, i) => {
+    const color = palette[i % palette.length];
+    const share = total ? item.value / total : 0;
+    if (share === 1) {
+      svg.append(svgElement("circle", { cx: radius + 5, cy: radius + 5, r: radius, fill: color }));
+    } else if (share > 0) {
+      const end = angle + share * 2 * Math.PI;
+      const point = (a) => `${radius + 5 + radius * Math.cos(a)} ${radius + 5 + radius * Math.sin(a)}`;
+      const largeArc = share > 0.5 ? 1 : 0;
+      svg.append(svgElement("path", {
+        d: `M ${radius + 5} ${radius + 5} L ${point(angle)} A ${radius} ${radius} 0 ${largeArc} 1 ${point(end)} Z`,
+        fill: color,
+      }));
+      angle = end;
+    }
+
+    const y = i * 20 + 5;
+    svg.append(
+      svgElement("rect", { x: 2 * radius + 25, y, width: 12, height: 12, fill: color }),
+      svgElement("text", { x: 2 * radius + 43, y: y + 10 }, `${item.label} (${(share * 100).toFixed(1)}%)`)
+    );
+  });
+  return figure(caption, svg);
+}
+
+// views turn each report document into charts and table rows.
+const views = {
+  stars(doc) {
+    const buckets = doc.buckets.map((b) => ({ label: b.bucket, value: b.repos }));
+    return {
+      charts: [
+        barChart("Repositories per star bucket", buckets),
+        pieChart("Share of the repositories per star bucket", buckets),
+      ],
+      header: ["bucket", "repos", "stars", "avg stars per repo"],
+      rows: doc.buckets.map((b) => [b.bucket, b.repos, b.stars, b.avg_stars_per_repo.toFixed(2)]),
+    };
+  },
+  licenses(doc) {
+    const licenses = doc.licenses.map((l) => ({ label: l.license, value: l.repos }));
+    return {
+      charts: [
+        pieChart("Share of the repositories per license", licenses),
+        barChart("Repositories per license", licenses),
+      ],
+      header: ["license", "repos"],
+      rows: doc.licenses.map((l) => [l.license, l.repos]),
+    };
+  },
+  owners(doc) {
+    return {
+      charts: [barChart("Repositories per owner", doc.owners.slice(0, 15).map((o) => ({ label: o.owner, value: o.repos })))],
+      header: ["owner", "type", "repos", "stars"],
+      rows: doc.owners.map((o) => [o.owner, o.type, o.repos, o.stars]),
+    };
+  },
+  crosstab(doc) {
+    return {
+      charts: [
+        barChart("Repositories per star bucket", doc.buckets.map((b) => ({ label: b.bucket, value: b.repos }))),
+        pieChart("Share of the repositories per license family", doc.families.map((f) => ({ label: f, value: doc.totals.families[f] }))),
+      ],
+      header: ["bucket", ...doc.families, "repos"],
+      rows: [...doc.buckets, doc.totals].map((b) => [b.bucket, ...doc.families.map((f) => b.families[f]), b.repos]),
+    };
+  },
+};
+
+function render(name, doc) {
+  const view = views[name](doc);
+
+  document.getElementById("title").textContent = `${doc.report}, repositories ${doc.since} to ${doc.max_id}`;
+  document.getElementById("charts").replaceChildren(...view.charts);
+
+  const table = document.getElementById("table");
+  const headerRow = document.createElement("tr");
+  view.header.forEach((h) => {
+    const th = document.createElement("th");
+    th.textContent = h;
+    headerRow.append(th);
+  });
+  table.tHead.replaceChildren(headerRow);
+
+  table.tBodies[0].replaceChildren(...view.rows.map((row) => {
+    const tr = document.createElement("tr");
+    row.forEach((cell) => {
+      const td = document.createElement("td");
+      td.textContent = typeof cell === "number" ? cell.toLocaleString() : cell;
+      if (typeof cell === "number") {
+        td.className = "number";
+      }
+      tr.append(td);
+    });
+    return tr;
+  }));
+
+  document.getElementById("result").hidden = false;
+}
+
+form.addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const data = new FormData(form);
+  const name = data.get("report");
+  data.delete("report");
+
+  const button = form.querySelector("button");
+  button.disabled = true;
+  setStatus("Crawling repositories...");
+  try {
+    render(name, await runReport(name, new URLSearchParams(data)));
+    setStatus("");
+  } catch (err) {
+    setStatus(err.message, true);
+  } finally {
+    button.disabled = false;
+  }
+});
+
+reportSelect.addEventListener("change", fillSortColumns);
+
+loadReports().catch((err) => setStatus(`Could not list the reports: ${err.message}`, true));
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	jobPending  = "pending"
+	jobRunning  = "running"
+	jobDone     = "done"
+	jobFailed   = "failed"
+	jobCanceled = "canceled"
+)
+
+// finishedJobTTL is how long finished jobs are kept, for their result to
+// be fetched, and maxFinishedJobs how many of them at most.
+var (
+	finishedJobTTL  = time.Hour
+	maxFinishedJobs = 100
+)
+
+// errJobsFull is returned when submitting a job while as many jobs as
+// allowed are already running or waiting for their turn.
+var errJobsFull = errors.New("too many report jobs are running or waiting, please try again later")
+
+// job is a report run in the background. It is also the body of
+// the job status responses.
+type job struct {
+	ID         string          `json:"id"`
+	Report     string          `json:"report"`
+	Since      int             `json:"since"`
+	MaxID      int             `json:"max_id"`
+	Status     string          `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// jobs runs report jobs, at most `slots` at a time, with at most `queued`
+// more waiting for their turn. Jobs are kept in memory, so their results
+// are lost when the server stops, and finished jobs are evicted once older
+// than finishedJobTTL, or beyond the maxFinishedJobs most recent ones.
+type jobs struct {
+	ctx    context.Context
+	slots  chan struct{}
+	queued int
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	byID map[string]*job
+	// finished are the finished jobs, oldest first.
+	finished []*job
+}
+
+func newJobs(ctx context.Context, slots, queued int) *jobs {
+	return &jobs{
+		ctx:    ctx,
+		slots:  make(chan struct{}, slots),
+		queued: queued,
+		byID:   make(map[string]*job),
+	}
+}
+
+// submit starts running `run` in the background as soon as there is a
+// free slot, and returns a copy of the new job, or errJobsFull when no
+// more jobs can wait for a slot.
+func (js *jobs) submit(req reportRequest, run func(context.Context) (json.RawMessage, error)) (job, error) {
+	j := &job{
+		ID:        newJobID(),
+		Report:    req.name,
+		Since:     req.opts.Since,
+		MaxID:     req.opts.MaxID,
+		Status:    jobPending,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	js.mu.Lock()
+	js.evict(time.Now())
+	if unfinished := len(js.byID) - len(js.finished); unfinished >= cap(js.slots)+js.queued {
+		js.mu.Unlock()
+		return job{}, errJobsFull
+	}
+	js.byID[j.ID] = j
+	snapshot := *j
+	js.mu.Unlock()
+
+	js.wg.Add(1)
+	go func() {
+		defer js.wg.Done()
+
+		select {
+		case js.slots <- struct{}{}:
+			defer func() { <-js.slots }()
+		case <-js.ctx.Done():
+			js.finish(j, nil, js.ctx.Err())
+			return
+		}
+
+		js.update(j, func(j *job) {
+			now := time.Now().UTC()
+			j.Status = jobRunning
+			j.StartedAt = &now
+		})
+		result, err := run(js.ctx)
+		js.finish(j, result, err)
+	}()
+
+	return snapshot, nil
+}
+
+func (js *jobs) finish(j *job, result json.RawMessage, err error) {
+	js.update(j, func(j *job) {
+		js.finished = append(js.finished, j)
+		now := time.Now().UTC()
+		j.FinishedAt = &now
+		switch {
+		case err != nil && js.ctx.Err() != nil:
+			j.Status = jobCanceled
+			j.Error = "the server is shutting down"
+		case err != nil:
+			j.Status = jobFailed
+			j.Error = err.Error()
+		default:
+			j.Status = jobDone
+			j.Result = result
+		}
+	})
+}
+
+func (js *jobs) update(j *job, f func(*job)) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	f(j)
+}
+
+// get returns a copy of the job with the given ID.
+func (js *jobs) get(id string) (job, bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.evict(time.Now())
+	j, ok := js.byID[id]
+	if !ok {
+		return job{}, false
+	}
+	return *j, true
+}
+
+// evict drops the finished jobs that are too old, or too many. It must
+// be called with the lock held.
+func (js *jobs) evict(now time.Time) {
+	n := 0
+	for n < len(js.finished) {
+		if len(js.finished)-n <= maxFinishedJobs && now.Sub(*js.finished[n].FinishedAt) < finishedJobTTL {
+			break
+		}
+		delete(js.byID, js.finished[n].ID)
+		n++
+	}
+	js.finished = js.finished[n:]
+}
+
+func (js *jobs) wait() {
+	js.wg.Wait()
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand only fails when the OS has no entropy source.
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
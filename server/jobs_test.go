@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Test_jobsEvict asserts that finished jobs are evicted once too old, or
+// beyond the most recent ones, and that running jobs never are.
+func Test_jobsEvict(t *testing.T) {
+	defer func(ttl time.Duration, max int) { finishedJobTTL, maxFinishedJobs = ttl, max }(finishedJobTTL, maxFinishedJobs)
+	finishedJobTTL, maxFinishedJobs = time.Hour, 2
+
+	js := newJobs(context.Background(), 4, 0)
+	release := make(chan struct{})
+	running, err := js.submit(reportRequest{name: "stars"}, func(context.Context) (json.RawMessage, error) {
+		<-release
+		return json.RawMessage(`{}`), nil
+	})
+	require.NoError(t, err)
+
+	var finished []job
+	for i := 0; i < 3; i++ {
+		j, err := js.submit(reportRequest{name: "licenses"}, func(context.Context) (json.RawMessage, error) {
+			return json.RawMessage(`{}`), nil
+		})
+		require.NoError(t, err)
+		finished = append(finished, j)
+		require.Eventually(t, func() bool {
+			j, _ := js.get(finished[i].ID)
+			return j.Status == jobDone
+		}, 5*time.Second, time.Millisecond)
+	}
+
+	// Only the 2 most recent finished jobs are kept.
+	_, ok := js.get(finished[0].ID)
+	require.False(t, ok)
+	for _, j := range finished[1:] {
+		_, ok := js.get(j.ID)
+		require.True(t, ok)
+	}
+
+	// Then none of them, once older than the TTL.
+	js.mu.Lock()
+	js.evict(time.Now().Add(finishedJobTTL))
+	js.mu.Unlock()
+	for _, j := range finished {
+		_, ok := js.get(j.ID)
+		require.False(t, ok)
+	}
+
+	j, ok := js.get(running.ID)
+	require.True(t, ok)
+	require.Contains(t, []string{jobPending, jobRunning}, j.Status)
+	close(release)
+	js.wait()
+}
+
+// Test_jobsFull asserts that jobs are refused once as many as allowed are
+// running or waiting for their turn, and accepted again once one finishes.
+func Test_jobsFull(t *testing.T) {
+	js := newJobs(context.Background(), 1, 1)
+	release := make(chan struct{})
+	blocked := func(context.Context) (json.RawMessage, error) {
+		<-release
+		return json.RawMessage(`{}`), nil
+	}
+
+	_, err := js.submit(reportRequest{name: "stars"}, blocked)
+	require.NoError(t, err)
+	_, err = js.submit(reportRequest{name: "stars"}, blocked)
+	require.NoError(t, err)
+	_, err = js.submit(reportRequest{name: "stars"}, blocked)
+	require.True(t, errors.Is(err, errJobsFull))
+
+	release <- struct{}{}
+	require.Eventually(t, func() bool {
+		js.mu.Lock()
+		defer js.mu.Unlock()
+		return len(js.finished) == 1
+	}, 5*time.Second, time.Millisecond)
+	_, err = js.submit(reportRequest{name: "stars"}, blocked)
+	require.NoError(t, err)
+
+	close(release)
+	js.wait()
+}
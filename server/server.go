@@ -0,0 +1,287 @@
+// Package server exposes the reports as JSON endpoints, along with a
+// dashboard, served at /, charting them:
+//
+//	GET  /reports                  lists the report names
+//	GET  /reports/{name}?since=&max_id=&sort=&order=&top=
+//	                               runs a report, or submits it as a job
+//	                               when its range is too long to wait for
+//	POST /jobs?report={name}&...   submits a report job
+//	GET  /jobs/{id}                returns the status, and result, of a job
+//	GET  /metrics                  returns the metrics, when collected
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/metrics"
+)
+
+// defaultMaxQueuedJobs is the number of jobs that can wait for their turn
+// when Options.MaxQueuedJobs isn't set.
+const defaultMaxQueuedJobs = 10
+
+// Options configures the server.
+type Options struct {
+	// SyncMaxIDs is the longest ID range a report is run for while the
+	// request waits. Longer ranges are submitted as jobs.
+	SyncMaxIDs int
+	// MaxJobs is the number of jobs that can run at the same time,
+	// further jobs wait for their turn.
+	MaxJobs int
+	// MaxQueuedJobs is the number of jobs that can wait for their turn,
+	// defaultMaxQueuedJobs when 0. Further jobs are refused with a 503.
+	MaxQueuedJobs int
+	// Metrics, when set, records the report runs and is served
+	// on /metrics.
+	Metrics *metrics.Collector
+	// Defaults are the parameters the dashboard form is filled with.
+	Defaults Defaults
+}
+
+// Defaults are the report parameters the dashboard starts with, e.g. the
+// ones of the active profile. Empty ones are left to the dashboard.
+type Defaults struct {
+	Report string `json:"report,omitempty"`
+	Since  int    `json:"since"`
+	MaxID  int    `json:"max_id"`
+	Sort   string `json:"sort,omitempty"`
+	Order  string `json:"order,omitempty"`
+}
+
+// Server runs reports against the GitHub API for HTTP requests.
+type Server struct {
+	gh   *github.Github
+	opts Options
+	jobs *jobs
+	mux  *http.ServeMux
+}
+
+// New returns a server that runs reports with `gh`. Jobs are canceled
+// when `ctx` is done.
+func New(ctx context.Context, gh *github.Github, opts Options) *Server {
+	if opts.MaxJobs < 1 {
+		opts.MaxJobs = 1
+	}
+	if opts.MaxQueuedJobs < 1 {
+		opts.MaxQueuedJobs = defaultMaxQueuedJobs
+	}
+
+	s := &Server{
+		gh:   gh,
+		opts: opts,
+		jobs: newJobs(ctx, opts.MaxJobs, opts.MaxQueuedJobs),
+		mux:  http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/reports", s.handleReportNames)
+	s.mux.HandleFunc("/reports/", s.handleReport)
+	s.mux.HandleFunc("/jobs", s.handleSubmitJob)
+	s.mux.HandleFunc("/jobs/", s.handleJob)
+	if opts.Metrics != nil {
+		s.mux.Handle("/metrics", opts.Metrics.Handler())
+	}
+	s.mux.Handle("/", dashboardHandler())
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Wait blocks until all the running jobs have returned.
+func (s *Server) Wait() {
+	s.jobs.wait()
+}
+
+func (s *Server) handleReportNames(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+
+	names := make([]string, 0, len(analytics.ReportTypes))
+	for name := range analytics.ReportTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, struct {
+		Reports  []string `json:"reports"`
+		Defaults Defaults `json:"defaults"`
+	}{names, s.opts.Defaults})
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+
+	req, err := parseReportRequest(strings.TrimPrefix(r.URL.Path, "/reports/"), r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.opts.MaxID-req.opts.Since > s.opts.SyncMaxIDs {
+		s.submit(w, req)
+		return
+	}
+
+	result, err := s.runReport(r.Context(), req)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+
+	query := r.URL.Query()
+	req, err := parseReportRequest(query.Get("report"), query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.submit(w, req)
+}
+
+func (s *Server) submit(w http.ResponseWriter, req reportRequest) {
+	j, err := s.jobs.submit(req, func(ctx context.Context) (json.RawMessage, error) {
+		return s.runReport(ctx, req)
+	})
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+j.ID)
+	writeJSON(w, http.StatusAccepted, j)
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	j, ok := s.jobs.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %q not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, j)
+}
+
+// reportRequest is a validated request for a report.
+type reportRequest struct {
+	name       string
+	reportType string
+	opts       analytics.ParamOptions
+}
+
+func parseReportRequest(name string, q url.Values) (reportRequest, error) {
+	reportType, ok := analytics.ReportTypes[name]
+	if !ok {
+		return reportRequest{}, fmt.Errorf("unknown report %q", name)
+	}
+	req := reportRequest{name: name, reportType: reportType, opts: analytics.ParamOptions{Asc: true}}
+
+	var err error
+	if req.opts.Since, err = intParam(q, "since", true); err != nil {
+		return reportRequest{}, err
+	}
+	if req.opts.MaxID, err = intParam(q, "max_id", true); err != nil {
+		return reportRequest{}, err
+	}
+	if req.opts.Top, err = intParam(q, "top", false); err != nil {
+		return reportRequest{}, err
+	}
+	if err := analytics.ValidateIDRange(req.opts.Since, req.opts.MaxID); err != nil {
+		return reportRequest{}, err
+	}
+
+	req.opts.Column = q.Get("sort")
+	switch order := q.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		req.opts.Asc = false
+	default:
+		return reportRequest{}, fmt.Errorf("unknown order %q, please use asc or desc", order)
+	}
+
+	// Validates the sort column.
+	if _, err := analytics.NewReport(req.reportType, req.opts); err != nil {
+		return reportRequest{}, err
+	}
+	return req, nil
+}
+
+func intParam(q url.Values, key string, required bool) (int, error) {
+	v := q.Get(key)
+	if v == "" {
+		if required {
+			return 0, fmt.Errorf("the `%s` parameter is required", key)
+		}
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("the `%s` parameter must be a positive integer, got %q", key, v)
+	}
+	return n, nil
+}
+
+// runReport runs the report and returns its JSON document.
+func (s *Server) runReport(ctx context.Context, req reportRequest) (json.RawMessage, error) {
+	report, err := analytics.NewReport(req.reportType, req.opts)
+	if err != nil {
+		return nil, err
+	}
+	exporter, ok := report.(analytics.Exporter)
+	if !ok {
+		return nil, fmt.Errorf("the %s cannot be written as JSON", report.Name())
+	}
+
+	err = report.Run(ctx, s.gh)
+	if s.opts.Metrics != nil {
+		s.opts.Metrics.RecordRun(report, err, time.Now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := exporter.WriteJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// The status has been sent, an encoding error can't be
+	// reported to the client anymore.
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
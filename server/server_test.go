@@ -0,0 +1,134 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/github/githubtest"
+	"github.com/carlisia/ghinfo/server"
+)
+
+// newGithub serves repositories with IDs 1 up to lastID.
+func newGithub(t *testing.T, lastID int) *github.Github {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 1, Count: lastID, Owners: 10}))
+	t.Cleanup(server.Close)
+	return server.Github()
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := server.New(ctx, newGithub(t, 20), server.Options{SyncMaxIDs: 5, MaxJobs: 1})
+	srv := httptest.NewServer(s)
+	t.Cleanup(func() {
+		srv.Close()
+		cancel()
+		s.Wait()
+	})
+	return srv
+}
+
+func get(t *testing.T, url string, v interface{}) *http.Response {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp
+}
+
+func TestReportValidation(t *testing.T) {
+	srv := newServer(t)
+
+	testCases := []struct {
+		name          string
+		path          string
+		expectedError string
+	}{
+		{"unknown report", "/reports/forks?since=1&max_id=2", `unknown report "forks"`},
+		{"missing since", "/reports/stars?max_id=2", "the `since` parameter is required"},
+		{"invalid max_id", "/reports/stars?since=1&max_id=x", "the `max_id` parameter must be a positive integer"},
+		{"empty range", "/reports/stars?since=2&max_id=1", ""},
+		{"unknown order", "/reports/stars?since=1&max_id=2&order=up", `unknown order "up"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var body map[string]string
+			resp := get(t, srv.URL+tc.path, &body)
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			require.Contains(t, body["error"], tc.expectedError)
+		})
+	}
+}
+
+func TestReportSync(t *testing.T) {
+	srv := newServer(t)
+
+	var body map[string]interface{}
+	resp := get(t, srv.URL+"/reports/stars?since=0&max_id=5", &body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotEmpty(t, body)
+}
+
+func TestReportJob(t *testing.T) {
+	srv := newServer(t)
+
+	var submitted map[string]interface{}
+	resp := get(t, srv.URL+"/reports/licenses?since=0&max_id=20", &submitted)
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	location := resp.Header.Get("Location")
+	require.Equal(t, "/jobs/"+submitted["id"].(string), location)
+
+	var polled map[string]interface{}
+	require.Eventually(t, func() bool {
+		polled = nil
+		resp := get(t, srv.URL+location, &polled)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return polled["status"] == "done" || polled["status"] == "failed"
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "done", polled["status"], polled["error"])
+	require.NotEmpty(t, polled["result"])
+
+	var missing map[string]string
+	resp = get(t, srv.URL+"/jobs/nope", &missing)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+// TestReportNames asserts that the reports are listed along with the
+// defaults the dashboard form is filled with.
+func TestReportNames(t *testing.T) {
+	defaults := server.Defaults{Report: "owners", Since: 100, MaxID: 200, Sort: "stars", Order: "desc"}
+	srv := httptest.NewServer(server.New(context.Background(), newGithub(t, 1), server.Options{Defaults: defaults}))
+	t.Cleanup(srv.Close)
+
+	var body struct {
+		Reports  []string
+		Defaults server.Defaults
+	}
+	resp := get(t, srv.URL+"/reports", &body)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"crosstab", "licenses", "owners", "stars"}, body.Reports)
+	require.Equal(t, defaults, body.Defaults)
+}
+
+func TestDashboard(t *testing.T) {
+	srv := newServer(t)
+
+	for path, contentType := range map[string]string{
+		"/":              "text/html; charset=utf-8",
+		"/dashboard.js":  "text/javascript; charset=utf-8",
+		"/dashboard.css": "text/css; charset=utf-8",
+	} {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode, path)
+		require.Equal(t, contentType, resp.Header.Get("Content-Type"), path)
+	}
+}
@@ -0,0 +1,206 @@
+package snapshot
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/table"
+	"github.com/jedib0t/go-pretty/text"
+
+	"github.com/carlisia/ghinfo/github"
+)
+
+// Diff is what changed between two snapshots of the same ID range.
+type Diff struct {
+	From, To *Snapshot
+	// Buckets holds the totals per bucket in both snapshots.
+	Buckets []BucketChange
+	// LicenseChanges are the repositories whose license changed.
+	LicenseChanges []LicenseChange
+	// Gone are the repositories that are no longer listed, because they
+	// were deleted or made private.
+	Gone []Repo
+	// Unretrieved are the repositories listed in both snapshots that
+	// couldn't be enriched in one of them, so whose stars and license
+	// can't be compared.
+	Unretrieved []Repo
+	// Added are the repositories that were not listed before, because
+	// they were made public.
+	Added []Repo
+	// Migrations counts the repositories that moved between buckets.
+	Migrations []Migration
+}
+
+type BucketChange struct {
+	Bucket             string
+	FromRepos, ToRepos int
+	FromStars, ToStars int
+	// StarGrowth is the number of stars gained by the repositories that
+	// were in this bucket and are in both snapshots.
+	StarGrowth int
+}
+
+type LicenseChange struct {
+	Repo     Repo
+	FromName string
+}
+
+type Migration struct {
+	From, To string
+	Repos    int
+}
+
+// Compare returns the changes from snapshot `from` to snapshot `to`.
+func Compare(from, to *Snapshot) Diff {
+	d := Diff{From: from, To: to}
+
+	before := make(map[int]Repo, len(from.Repos))
+	for _, r := range from.Repos {
+		before[r.ID] = r
+	}
+	after := make(map[int]Repo, len(to.Repos))
+	for _, r := range to.Repos {
+		after[r.ID] = r
+	}
+
+	growth := make(map[string]int)
+	migrations := make(map[[2]string]int)
+	for _, a := range from.Repos {
+		b, ok := after[a.ID]
+		if !ok {
+			d.Gone = append(d.Gone, a)
+			continue
+		}
+		if !a.Enriched || !b.Enriched {
+			d.Unretrieved = append(d.Unretrieved, b)
+			continue
+		}
+
+		growth[a.Bucket] += b.Stars - a.Stars
+		if a.Bucket != b.Bucket {
+			migrations[[2]string{a.Bucket, b.Bucket}]++
+		}
+		if a.SpdxID != b.SpdxID || a.License != b.License {
+			d.LicenseChanges = append(d.LicenseChanges, LicenseChange{Repo: b, FromName: a.License})
+		}
+	}
+	for _, b := range to.Repos {
+		if _, ok := before[b.ID]; !ok {
+			d.Added = append(d.Added, b)
+		}
+	}
+
+	for _, tier := range github.BucketTiers {
+		a, b := from.Aggregates.Buckets[tier], to.Aggregates.Buckets[tier]
+		if a.Repos == 0 && b.Repos == 0 {
+			continue
+		}
+		d.Buckets = append(d.Buckets, BucketChange{
+			Bucket:     tier,
+			FromRepos:  a.Repos,
+			ToRepos:    b.Repos,
+			FromStars:  a.Stars,
+			ToStars:    b.Stars,
+			StarGrowth: growth[tier],
+		})
+	}
+
+	for k, n := range migrations {
+		d.Migrations = append(d.Migrations, Migration{From: k[0], To: k[1], Repos: n})
+	}
+	sort.Slice(d.Migrations, func(i, j int) bool {
+		if d.Migrations[i].From != d.Migrations[j].From {
+			return github.BucketTierIndex(d.Migrations[i].From) < github.BucketTierIndex(d.Migrations[j].From)
+		}
+		return github.BucketTierIndex(d.Migrations[i].To) < github.BucketTierIndex(d.Migrations[j].To)
+	})
+
+	return d
+}
+
+// Print writes the diff as tables.
+func (d Diff) Print(w io.Writer) {
+	fmt.Fprintf(w, "Comparing %s (taken at %s) to %s (taken at %s)\n",
+		d.From.ID, d.From.TakenAt.Format("2006-01-02 15:04 MST"), d.To.ID, d.To.TakenAt.Format("2006-01-02 15:04 MST"))
+	if d.From.Since != d.To.Since || d.From.MaxID != d.To.MaxID {
+		fmt.Fprintf(w, "Warning: the snapshots cover different ID ranges (%d to %d, and %d to %d)\n",
+			d.From.Since, d.From.MaxID, d.To.Since, d.To.MaxID)
+	}
+	fmt.Fprintln(w)
+
+	tw := newTable()
+	tw.AppendHeader(table.Row{"bucket", "#repos before", "#repos after", "stars before", "stars after", "star growth"})
+	var growth int
+	for _, b := range d.Buckets {
+		growth += b.StarGrowth
+		tw.AppendRow(table.Row{b.Bucket, b.FromRepos, b.ToRepos, b.FromStars, b.ToStars, signed(b.StarGrowth)})
+	}
+	tw.AppendFooter(table.Row{"total", len(d.From.Retrieved()), len(d.To.Retrieved()), "", "", signed(growth)})
+	fmt.Fprintf(w, "Star growth per bucket:\n%s\n\n", tw.Render())
+
+	if len(d.Migrations) > 0 {
+		tw = newTable()
+		tw.AppendHeader(table.Row{"from bucket", "to bucket", "#repos"})
+		for _, m := range d.Migrations {
+			tw.AppendRow(table.Row{m.From, m.To, m.Repos})
+		}
+		fmt.Fprintf(w, "Bucket migrations:\n%s\n\n", tw.Render())
+	} else {
+		fmt.Fprint(w, "No repositories moved between buckets.\n\n")
+	}
+
+	if len(d.LicenseChanges) > 0 {
+		tw = newTable()
+		tw.AppendHeader(table.Row{"repository", "license before", "license after"})
+		for _, c := range d.LicenseChanges {
+			tw.AppendRow(table.Row{c.Repo.FullName, orNone(c.FromName), orNone(c.Repo.License)})
+		}
+		fmt.Fprintf(w, "License changes:\n%s\n\n", tw.Render())
+	} else {
+		fmt.Fprint(w, "No licenses changed.\n\n")
+	}
+
+	if len(d.Unretrieved) > 0 {
+		fmt.Fprintf(w, "%d repositories listed in both snapshots couldn't be retrieved in one of them, and aren't compared.\n\n", len(d.Unretrieved))
+	}
+	printRepos(w, "Deleted or made private", d.Gone)
+	printRepos(w, "Made public", d.Added)
+}
+
+func printRepos(w io.Writer, title string, repos []Repo) {
+	if len(repos) == 0 {
+		fmt.Fprintf(w, "%s: none.\n\n", title)
+		return
+	}
+
+	tw := newTable()
+	tw.AppendHeader(table.Row{"id", "repository", "stars", "license"})
+	for _, r := range repos {
+		if !r.Enriched {
+			tw.AppendRow(table.Row{r.ID, r.FullName, "unknown", "unknown"})
+			continue
+		}
+		tw.AppendRow(table.Row{r.ID, r.FullName, r.Stars, orNone(r.License)})
+	}
+	fmt.Fprintf(w, "%s:\n%s\n\n", title, tw.Render())
+}
+
+func newTable() table.Writer {
+	tw := table.NewWriter()
+	tw.SetStyle(table.StyleRounded)
+	tw.Style().Format.Header = text.FormatLower
+	tw.Style().Format.Footer = text.FormatLower
+	return tw
+}
+
+func signed(n int) string {
+	return fmt.Sprintf("%+d", n)
+}
+
+func orNone(license string) string {
+	if license == "" {
+		return "none"
+	}
+	return license
+}
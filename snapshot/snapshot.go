@@ -0,0 +1,160 @@
+// Package snapshot persists the results of report runs, and compares
+// them over time.
+package snapshot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github"
+)
+
+// Snapshot is the saved result of a report run: what was queried, when,
+// the data of each listed repository and the aggregates built from the
+// enriched ones.
+type Snapshot struct {
+	ID         string `json:"id"`
+	Report     string `json:"report"`
+	ReportType string `json:"report_type"`
+	Since      int    `json:"since"`
+	MaxID      int    `json:"max_id"`
+	// Sort, Order and Top are the options the report was printed with,
+	// the defaults of its rendering.
+	Sort       string     `json:"sort,omitempty"`
+	Order      string     `json:"order,omitempty"`
+	Top        int        `json:"top,omitempty"`
+	TakenAt    time.Time  `json:"taken_at"`
+	Repos      []Repo     `json:"repos"`
+	Aggregates Aggregates `json:"aggregates"`
+}
+
+// Repo is the data saved for each repository. Only the listing data is
+// saved for the repositories that couldn't be enriched, e.g. because of a
+// transient error, which are still listed.
+type Repo struct {
+	ID        int    `json:"id"`
+	FullName  string `json:"full_name"`
+	Owner     string `json:"owner"`
+	OwnerType string `json:"owner_type"`
+	Enriched  bool   `json:"enriched"`
+	Stars     int    `json:"stars"`
+	Bucket    string `json:"bucket"`
+	License   string `json:"license"`
+	SpdxID    string `json:"spdx_id"`
+}
+
+// Aggregates are the totals per star bucket and per license.
+type Aggregates struct {
+	Buckets  map[string]BucketTotals `json:"buckets"`
+	Licenses map[string]int          `json:"licenses"`
+}
+
+type BucketTotals struct {
+	Repos int `json:"repos"`
+	Stars int `json:"stars"`
+}
+
+// New returns a snapshot of the results of a report run.
+func New(results analytics.Results, takenAt time.Time) *Snapshot {
+	opts := results.Options
+	s := &Snapshot{
+		ID:         fmt.Sprintf("%s-%s-%d-%d", takenAt.UTC().Format("20060102T150405Z"), results.ReportType, opts.Since, opts.MaxID),
+		Report:     results.ReportName,
+		ReportType: results.ReportType,
+		Since:      opts.Since,
+		MaxID:      opts.MaxID,
+		Sort:       opts.Column,
+		Order:      "asc",
+		Top:        opts.Top,
+		TakenAt:    takenAt.UTC(),
+	}
+	if !opts.Asc {
+		s.Order = "desc"
+	}
+
+	enriched := make(map[int]github.Repos, len(results.Enriched))
+	for _, r := range results.Enriched {
+		enriched[r.ID] = r
+	}
+	for _, r := range results.Listed {
+		repo := Repo{
+			ID:        r.ID,
+			FullName:  r.FullName,
+			Owner:     r.Owner.Login,
+			OwnerType: r.Owner.Type,
+		}
+		if full, ok := enriched[r.ID]; ok {
+			repo.Enriched = true
+			repo.Stars = full.StargazersCount
+			repo.Bucket = github.BucketTier(full.StargazersCount)
+			repo.License = full.License.Name
+			repo.SpdxID = full.License.SpdxID
+		}
+		s.Repos = append(s.Repos, repo)
+	}
+	s.Aggregates = aggregate(s.Repos)
+
+	return s
+}
+
+// Results returns the results the snapshot was taken of, as far as they
+// are saved, e.g. to build the report again with analytics.Rebuild.
+func (s *Snapshot) Results() analytics.Results {
+	results := analytics.Results{
+		ReportName: s.Report,
+		ReportType: s.ReportType,
+		Options: analytics.ParamOptions{
+			Since:  s.Since,
+			MaxID:  s.MaxID,
+			Column: s.Sort,
+			Asc:    s.Order != "desc",
+			Top:    s.Top,
+		},
+	}
+	for _, r := range s.Repos {
+		repo := github.Repos{
+			ID:       r.ID,
+			Name:     strings.TrimPrefix(r.FullName, r.Owner+"/"),
+			FullName: r.FullName,
+			Owner:    github.Owner{Login: r.Owner, Type: r.OwnerType},
+		}
+		results.Listed = append(results.Listed, repo)
+		if r.Enriched {
+			repo.StargazersCount = r.Stars
+			repo.License = github.License{Name: r.License, SpdxID: r.SpdxID}
+			results.Enriched = append(results.Enriched, repo)
+		}
+	}
+	return results
+}
+
+// Retrieved returns the repositories that were enriched.
+func (s *Snapshot) Retrieved() []Repo {
+	var repos []Repo
+	for _, r := range s.Repos {
+		if r.Enriched {
+			repos = append(repos, r)
+		}
+	}
+	return repos
+}
+
+func aggregate(repos []Repo) Aggregates {
+	a := Aggregates{
+		Buckets:  make(map[string]BucketTotals),
+		Licenses: make(map[string]int),
+	}
+	for _, r := range repos {
+		if !r.Enriched {
+			continue
+		}
+		b := a.Buckets[r.Bucket]
+		b.Repos++
+		b.Stars += r.Stars
+		a.Buckets[r.Bucket] = b
+		a.Licenses[r.License]++
+	}
+	return a
+}
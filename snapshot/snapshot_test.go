@@ -0,0 +1,130 @@
+package snapshot_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/snapshot"
+)
+
+func newSnapshot(id string, takenAt time.Time, repos ...snapshot.Repo) *snapshot.Snapshot {
+	s := &snapshot.Snapshot{ID: id, Since: 1, MaxID: 10, TakenAt: takenAt, Repos: repos,
+		Aggregates: snapshot.Aggregates{Buckets: map[string]snapshot.BucketTotals{}, Licenses: map[string]int{}}}
+	for _, r := range repos {
+		if !r.Enriched {
+			continue
+		}
+		b := s.Aggregates.Buckets[r.Bucket]
+		b.Repos++
+		b.Stars += r.Stars
+		s.Aggregates.Buckets[r.Bucket] = b
+		s.Aggregates.Licenses[r.License]++
+	}
+	return s
+}
+
+// TestCompare asserts that comparing two snapshots finds the star growth,
+// bucket migrations, license changes and the repositories that came and went,
+// not taking the repositories that couldn't be retrieved for gone.
+func TestCompare(t *testing.T) {
+	before := newSnapshot("a", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		snapshot.Repo{ID: 2, FullName: "o/steady", Enriched: true, Stars: 5, Bucket: "0..10", License: "MIT License", SpdxID: "MIT"},
+		snapshot.Repo{ID: 3, FullName: "o/rising", Enriched: true, Stars: 8, Bucket: "0..10"},
+		snapshot.Repo{ID: 4, FullName: "o/deleted", Enriched: true, Stars: 50, Bucket: "10..100"},
+		snapshot.Repo{ID: 6, FullName: "o/flaky", Enriched: true, Stars: 3, Bucket: "0..10"},
+	)
+	after := newSnapshot("b", time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		snapshot.Repo{ID: 2, FullName: "o/steady", Enriched: true, Stars: 6, Bucket: "0..10", License: "Apache License 2.0", SpdxID: "Apache-2.0"},
+		snapshot.Repo{ID: 3, FullName: "o/rising", Enriched: true, Stars: 120, Bucket: "100..1000"},
+		snapshot.Repo{ID: 5, FullName: "o/published", Enriched: true, Stars: 1, Bucket: "0..10"},
+		snapshot.Repo{ID: 6, FullName: "o/flaky"},
+	)
+
+	d := snapshot.Compare(before, after)
+
+	require.Equal(t, []snapshot.BucketChange{
+		{Bucket: "0..10", FromRepos: 3, ToRepos: 2, FromStars: 16, ToStars: 7, StarGrowth: 113},
+		{Bucket: "10..100", FromRepos: 1, ToRepos: 0, FromStars: 50, ToStars: 0, StarGrowth: 0},
+		{Bucket: "100..1000", FromRepos: 0, ToRepos: 1, FromStars: 0, ToStars: 120, StarGrowth: 0},
+	}, d.Buckets)
+	require.Equal(t, []snapshot.Migration{{From: "0..10", To: "100..1000", Repos: 1}}, d.Migrations)
+	require.Len(t, d.LicenseChanges, 1)
+	require.Equal(t, "o/steady", d.LicenseChanges[0].Repo.FullName)
+	require.Equal(t, "MIT License", d.LicenseChanges[0].FromName)
+	require.Equal(t, []snapshot.Repo{before.Repos[2]}, d.Gone)
+	require.Equal(t, []snapshot.Repo{after.Repos[2]}, d.Added)
+	require.Equal(t, []snapshot.Repo{after.Repos[3]}, d.Unretrieved)
+}
+
+// TestStore asserts that saved snapshots can be listed, oldest first,
+// and loaded back by ID or by path.
+func TestStore(t *testing.T) {
+	st := snapshot.Store{Dir: t.TempDir()}
+
+	ids, err := st.List()
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	newer := newSnapshot("20210201T000000Z-1-1-10", time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		snapshot.Repo{ID: 2, FullName: "o/r", Enriched: true, Stars: 5, Bucket: "0..10"})
+	older := newSnapshot("20210101T000000Z-1-1-10", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	path, err := st.Save(newer)
+	require.NoError(t, err)
+	_, err = st.Save(older)
+	require.NoError(t, err)
+
+	ids, err = st.List()
+	require.NoError(t, err)
+	require.Equal(t, []string{older.ID, newer.ID}, ids)
+
+	byID, err := st.Load(newer.ID)
+	require.NoError(t, err)
+	require.Equal(t, newer, byID)
+
+	byPath, err := st.Load(path)
+	require.NoError(t, err)
+	require.Equal(t, newer, byPath)
+
+	_, err = st.Load("missing")
+	require.Error(t, err)
+}
+
+// TestResults asserts that the report of a snapshot can be rebuilt from
+// the results it was taken of, including the listed repositories that
+// couldn't be enriched.
+func TestResults(t *testing.T) {
+	a := github.Repos{ID: 2, Name: "a", FullName: "o/a", Owner: github.Owner{Login: "o", Type: "User"}}
+	b := github.Repos{ID: 3, Name: "b", FullName: "o/b", Owner: github.Owner{Login: "o", Type: "User"}}
+	c := github.Repos{ID: 4, Name: "c", FullName: "org/c", Owner: github.Owner{Login: "org", Type: "Organization"}}
+	enrichedA, enrichedC := a, c
+	enrichedA.StargazersCount = 5
+	enrichedA.License = github.License{Name: "MIT License", SpdxID: "MIT"}
+	enrichedC.StargazersCount = 50
+	results := analytics.Results{
+		ReportName: "Owners Report",
+		ReportType: analytics.OwnerReportType,
+		Options:    analytics.ParamOptions{Since: 1, MaxID: 10, Column: "stars"},
+		Listed:     []github.Repos{a, b, c},
+		Enriched:   []github.Repos{enrichedA, enrichedC},
+	}
+	s := snapshot.New(results, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.Len(t, s.Repos, 3)
+	require.False(t, s.Repos[1].Enriched)
+	require.Equal(t, map[string]snapshot.BucketTotals{"0..10": {Repos: 1, Stars: 5}, "10..100": {Repos: 1, Stars: 50}}, s.Aggregates.Buckets)
+
+	got := s.Results()
+	require.Equal(t, results.Enriched, got.Enriched)
+	require.Equal(t, results.Listed, got.Listed)
+	require.Equal(t, analytics.ParamOptions{Since: 1, MaxID: 10, Column: "stars"}, got.Options)
+
+	got.Options.Column = "repos"
+	report, err := analytics.Rebuild(got)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Count())
+	require.Equal(t, results.Enriched, report.(analytics.Recorder).Results().Enriched)
+}
@@ -0,0 +1,94 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const fileExt = ".json"
+
+// Store saves snapshots as JSON files in a local directory.
+type Store struct {
+	Dir string
+}
+
+// DefaultDir returns the directory snapshots are stored in when
+// none is given.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "ghinfo", "snapshots"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "ghinfo", "snapshots"), nil
+}
+
+// Save writes the snapshot to the store, and returns its path.
+func (st Store) Save(s *Snapshot) (string, error) {
+	if err := os.MkdirAll(st.Dir, 0o755); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(st.Dir, s.ID+fileExt)
+	if err := ioutil.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Load reads a snapshot, either by its ID or by the path to its file.
+func (st Store) Load(idOrPath string) (*Snapshot, error) {
+	path := idOrPath
+	if !strings.HasSuffix(path, fileExt) {
+		path = filepath.Join(st.Dir, idOrPath+fileExt)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("snapshot %q not found in %s", idOrPath, st.Dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("reading snapshot %q: %w", idOrPath, err)
+	}
+	return &s, nil
+}
+
+// List returns the IDs of the stored snapshots, oldest first.
+func (st Store) List() ([]string, error) {
+	entries, err := ioutil.ReadDir(st.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != fileExt {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(e.Name(), fileExt))
+	}
+	// IDs start with the time the snapshot was taken at.
+	sort.Strings(ids)
+	return ids, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/snapshot"
+)
+
+// registerStore adds the flag selecting the snapshot store directory.
+func registerStore(fs *flag.FlagSet) *string {
+	return fs.String("store", "", "directory snapshots are saved in (default $XDG_DATA_HOME/ghinfo/snapshots)")
+}
+
+func openStore(dir string) (snapshot.Store, error) {
+	if dir != "" {
+		return snapshot.Store{Dir: dir}, nil
+	}
+
+	dir, err := snapshot.DefaultDir()
+	if err != nil {
+		return snapshot.Store{}, err
+	}
+	return snapshot.Store{Dir: dir}, nil
+}
+
+// saveSnapshot saves the results of a report that has been run. Reports
+// that don't keep per repository data, like sampled reports, are skipped.
+func saveSnapshot(report analytics.StatsReport, dir string) error {
+	recorder, ok := report.(analytics.Recorder)
+	if !ok {
+		return nil
+	}
+
+	store, err := openStore(dir)
+	if err != nil {
+		return err
+	}
+	path, err := store.Save(snapshot.New(recorder.Results(), time.Now()))
+	if err != nil {
+		return fmt.Errorf("error trying to save a snapshot of the report: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "\nA snapshot of this report was saved to %s\n", path)
+	return nil
+}
+
+func snapshotsCommand(args []string) error {
+	fs := flag.NewFlagSet("snapshots", flag.ContinueOnError)
+	dir := registerStore(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := openStore(*dir)
+	if err != nil {
+		return err
+	}
+	ids, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 0 {
+		fmt.Printf("There are no snapshots in %s yet.\n", store.Dir)
+		return nil
+	}
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+	return nil
+}
+
+func diffCommand(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	dir := registerStore(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("please name the two snapshots to compare: ghinfo diff [flags] <snapshotA> <snapshotB>")
+	}
+
+	store, err := openStore(*dir)
+	if err != nil {
+		return err
+	}
+	from, err := store.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	to, err := store.Load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	snapshot.Compare(from, to).Print(os.Stdout)
+	return nil
+}
+
+// renderCommand prints the report of a snapshot again, rebuilt from the
+// saved repositories instead of crawling them, so that it can be sorted
+// or written differently.
+func renderCommand(args []string) error {
+	// The snapshot can be named before the flags.
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	column := fs.String("sort", "", "column to order the report by")
+	order := fs.String("order", "asc", "order to sort by, asc or desc")
+	desc := fs.Bool("desc", false, "sort in descending order, same as --order desc")
+	top := fs.Int("top", 0, "only print the first N rows once sorted, for the owners report")
+	outputFlag := fs.String("output", "table", outputUsage)
+	chart := fs.Bool("chart", false, chartUsage)
+	dir := registerStore(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if name == "" && fs.NArg() == 1 {
+		name = fs.Arg(0)
+	} else if name == "" || fs.NArg() > 0 {
+		return errors.New("please name the snapshot to render, by ID or path: ghinfo render <snapshot> [flags]")
+	}
+
+	if *order != "asc" && *order != "desc" {
+		return fmt.Errorf("unknown order %q, please use asc or desc", *order)
+	}
+	out, err := parseOutput(*outputFlag)
+	if err != nil {
+		return err
+	}
+	if *chart && out.format != "table" {
+		return fmt.Errorf("charts can only be printed below a table, not with --output %s", *outputFlag)
+	}
+
+	store, err := openStore(*dir)
+	if err != nil {
+		return err
+	}
+	s, err := store.Load(name)
+	if err != nil {
+		return err
+	}
+
+	results := s.Results()
+	// The report is printed as it was, unless the flags say otherwise.
+	given := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	if given["sort"] {
+		results.Options.Column = *column
+	}
+	if given["order"] || given["desc"] {
+		results.Options.Asc = *order == "asc" && !*desc
+	}
+	if given["top"] {
+		results.Options.Top = *top
+	}
+	results.Options.Chart = *chart
+	report, err := analytics.Rebuild(results)
+	if err != nil {
+		return fmt.Errorf("error trying to rebuild the report of snapshot %s: %w", s.ID, err)
+	}
+	if err := out.check(report); err != nil {
+		return err
+	}
+	header := analytics.Header{Since: s.Since, MaxID: s.MaxID, At: s.TakenAt, Source: "snapshot " + s.ID}
+	return out.write(context.Background(), report, header)
+}
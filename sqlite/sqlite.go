@@ -0,0 +1,151 @@
+// Package sqlite writes the repositories crawled by a report run to a
+// SQLite database, for ad-hoc queries beyond the built-in reports.
+//
+// Each run appends to the database: repositories are stored per run, while
+// owners and licenses are shared by all runs.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	// Registers the pure Go `sqlite` driver.
+	_ "modernc.org/sqlite"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	report      TEXT    NOT NULL,
+	report_type TEXT    NOT NULL,
+	since       INTEGER NOT NULL,
+	max_id      INTEGER NOT NULL,
+	taken_at    TEXT    NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS owners (
+	id    INTEGER PRIMARY KEY,
+	login TEXT NOT NULL,
+	type  TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS licenses (
+	key     TEXT PRIMARY KEY,
+	name    TEXT NOT NULL,
+	spdx_id TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS repos (
+	run_id      INTEGER NOT NULL REFERENCES runs (id),
+	id          INTEGER NOT NULL,
+	name        TEXT    NOT NULL,
+	full_name   TEXT    NOT NULL,
+	owner_id    INTEGER NOT NULL REFERENCES owners (id),
+	-- enriched is 0 when the star count and license could not be
+	-- retrieved, in which case they are NULL.
+	enriched    INTEGER NOT NULL,
+	stars       INTEGER,
+	license_key TEXT REFERENCES licenses (key),
+	PRIMARY KEY (run_id, id)
+);
+
+CREATE INDEX IF NOT EXISTS repos_owner_id ON repos (owner_id);
+CREATE INDEX IF NOT EXISTS repos_license_key ON repos (license_key);
+`
+
+// Write appends the results of a report run to the database at path,
+// creating it when needed, and returns the ID of the new run.
+func Write(ctx context.Context, path string, results analytics.Results, takenAt time.Time) (int64, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return 0, fmt.Errorf("creating the tables: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	runID, err := write(ctx, tx, results, takenAt)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	return runID, tx.Commit()
+}
+
+func write(ctx context.Context, tx *sql.Tx, results analytics.Results, takenAt time.Time) (int64, error) {
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO runs (report, report_type, since, max_id, taken_at) VALUES (?, ?, ?, ?, ?)`,
+		results.ReportName, results.ReportType, results.Options.Since, results.Options.MaxID,
+		takenAt.UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("inserting the run: %w", err)
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	insertOwner, err := tx.PrepareContext(ctx,
+		`INSERT INTO owners (id, login, type) VALUES (?, ?, ?)
+		 ON CONFLICT (id) DO UPDATE SET login = excluded.login, type = excluded.type`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertOwner.Close()
+
+	insertLicense, err := tx.PrepareContext(ctx,
+		`INSERT INTO licenses (key, name, spdx_id) VALUES (?, ?, ?)
+		 ON CONFLICT (key) DO UPDATE SET name = excluded.name, spdx_id = excluded.spdx_id`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertLicense.Close()
+
+	insertRepo, err := tx.PrepareContext(ctx,
+		`INSERT INTO repos (run_id, id, name, full_name, owner_id, enriched, stars, license_key)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer insertRepo.Close()
+
+	enriched := make(map[int]github.Repos, len(results.Enriched))
+	for _, r := range results.Enriched {
+		enriched[r.ID] = r
+	}
+
+	for _, r := range results.Listed {
+		if _, err := insertOwner.ExecContext(ctx, r.Owner.ID, r.Owner.Login, r.Owner.Type); err != nil {
+			return 0, fmt.Errorf("inserting the owner of %s: %w", r.FullName, err)
+		}
+
+		var stars, licenseKey interface{}
+		full, ok := enriched[r.ID]
+		if ok {
+			stars = full.StargazersCount
+			if full.License.Key != "" {
+				licenseKey = full.License.Key
+				if _, err := insertLicense.ExecContext(ctx, full.License.Key, full.License.Name, full.License.SpdxID); err != nil {
+					return 0, fmt.Errorf("inserting the license of %s: %w", r.FullName, err)
+				}
+			}
+		}
+
+		if _, err := insertRepo.ExecContext(ctx, runID, r.ID, r.Name, r.FullName, r.Owner.ID, ok, stars, licenseKey); err != nil {
+			return 0, fmt.Errorf("inserting %s: %w", r.FullName, err)
+		}
+	}
+
+	return runID, nil
+}
@@ -0,0 +1,89 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github"
+	"github.com/carlisia/ghinfo/sqlite"
+)
+
+// TestWrite asserts that every listed repository is written, along with
+// its owner and, when it could be enriched, its stars and license.
+func TestWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ghinfo.db")
+	mit := github.License{Key: "mit", Name: "MIT License", SpdxID: "MIT"}
+	owner := github.Owner{ID: 7, Login: "octo", Type: "Organization"}
+
+	results := analytics.Results{
+		ReportName: "StarGazers Report",
+		ReportType: analytics.StarGazersReportType,
+		Options:    analytics.ParamOptions{Since: 1, MaxID: 10},
+		Listed: []github.Repos{
+			{ID: 2, Name: "a", FullName: "octo/a", Owner: owner},
+			{ID: 3, Name: "b", FullName: "octo/b", Owner: owner},
+			{ID: 4, Name: "c", FullName: "octo/c", Owner: owner},
+		},
+		Enriched: []github.Repos{
+			{ID: 2, Name: "a", FullName: "octo/a", Owner: owner, StargazersCount: 5, License: mit},
+			{ID: 3, Name: "b", FullName: "octo/b", Owner: owner, StargazersCount: 1},
+		},
+	}
+
+	ctx := context.Background()
+	takenAt := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	runID, err := sqlite.Write(ctx, path, results, takenAt)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, runID)
+
+	// Runs are appended to the same database.
+	runID, err = sqlite.Write(ctx, path, results, takenAt.Add(time.Hour))
+	require.NoError(t, err)
+	require.EqualValues(t, 2, runID)
+
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	rows, err := db.Query(`
+		SELECT r.full_name, o.login, r.enriched, r.stars, l.spdx_id
+		FROM repos r
+		JOIN owners o ON o.id = r.owner_id
+		LEFT JOIN licenses l ON l.key = r.license_key
+		WHERE r.run_id = 2
+		ORDER BY r.id`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	type row struct {
+		fullName, login string
+		enriched        bool
+		stars           sql.NullInt64
+		spdxID          sql.NullString
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		require.NoError(t, rows.Scan(&r.fullName, &r.login, &r.enriched, &r.stars, &r.spdxID))
+		got = append(got, r)
+	}
+	require.NoError(t, rows.Err())
+
+	require.Equal(t, []row{
+		{fullName: "octo/a", login: "octo", enriched: true, stars: sql.NullInt64{Int64: 5, Valid: true}, spdxID: sql.NullString{String: "MIT", Valid: true}},
+		{fullName: "octo/b", login: "octo", enriched: true, stars: sql.NullInt64{Int64: 1, Valid: true}},
+		{fullName: "octo/c", login: "octo"},
+	}, got)
+
+	var owners, licenses int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM owners`).Scan(&owners))
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM licenses`).Scan(&licenses))
+	require.Equal(t, 1, owners)
+	require.Equal(t, 1, licenses)
+}
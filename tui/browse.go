@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/text"
+
+	"github.com/carlisia/ghinfo/analytics"
+)
+
+// browsable is a report that can be browsed: it can be printed as a table,
+// and sorted again.
+type browsable interface {
+	analytics.StatsReport
+	analytics.Sortable
+	analytics.Tabular
+}
+
+// Browser shows the table of a report that has been run, which can be
+// sorted by any of its columns, and scrolled.
+type Browser struct {
+	report browsable
+	// offset is the first line of the table shown.
+	offset int
+	// page is the number of table lines that fit the screen, as of the
+	// last view.
+	page int
+	err  string
+}
+
+// NewBrowser returns a browser of the report, which must be Sortable and
+// Tabular.
+func NewBrowser(report analytics.StatsReport) (*Browser, error) {
+	r, ok := report.(browsable)
+	if !ok {
+		return nil, errors.New("the " + report.Name() + " can't be browsed")
+	}
+	return &Browser{report: r}, nil
+}
+
+// Browse shows the report table until the user quits.
+func (t *Terminal) Browse(b *Browser) error {
+	return t.run(b, nil)
+}
+
+func (b *Browser) update(k Key) bool {
+	b.err = ""
+	columns := b.report.Columns()
+	column, asc := b.report.SortedBy()
+	current := 0
+	for i, c := range columns {
+		if c == column {
+			current = i
+		}
+	}
+
+	switch {
+	case k.Code == KeyEsc || k.Code == KeyCtrlC || k.Code == KeyRune && k.Rune == 'q':
+		return true
+	case k.Code == KeyLeft || k.Code == KeyShiftTab:
+		b.sort(columns[(current+len(columns)-1)%len(columns)], asc)
+	case k.Code == KeyRight || k.Code == KeyTab:
+		b.sort(columns[(current+1)%len(columns)], asc)
+	case k.Code == KeyRune && k.Rune >= '1' && int(k.Rune-'1') < len(columns):
+		b.sort(columns[k.Rune-'1'], asc)
+	case k.Code == KeyRune && (k.Rune == ' ' || k.Rune == 'r'):
+		b.sort(column, !asc)
+	case k.Code == KeyUp || k.Code == KeyRune && k.Rune == 'k':
+		b.scroll(-1)
+	case k.Code == KeyDown || k.Code == KeyRune && k.Rune == 'j':
+		b.scroll(1)
+	case k.Code == KeyPageUp:
+		b.scroll(-b.page)
+	case k.Code == KeyPageDown:
+		b.scroll(b.page)
+	case k.Code == KeyHome:
+		b.offset = 0
+	case k.Code == KeyEnd:
+		b.scroll(len(b.lines()))
+	}
+	return false
+}
+
+func (b *Browser) sort(column string, asc bool) {
+	if err := b.report.Sort(column, asc); err != nil {
+		b.err = err.Error()
+	}
+}
+
+// scroll moves the table by the given number of lines, as long as the
+// last one is not above the bottom of the screen.
+func (b *Browser) scroll(by int) {
+	b.offset += by
+	if max := len(b.lines()) - b.page; b.offset > max {
+		b.offset = max
+	}
+	if b.offset < 0 {
+		b.offset = 0
+	}
+}
+
+func (b *Browser) lines() []string {
+	return strings.Split(b.report.Table().Render(), "\n")
+}
+
+func (b *Browser) view(width, height int) []string {
+	column, asc := b.report.SortedBy()
+	order := "asc"
+	if !asc {
+		order = "desc"
+	}
+
+	var columns []string
+	for i, c := range b.report.Columns() {
+		label := fmt.Sprintf(" %d %s ", i+1, c)
+		if c == column {
+			label = text.Colors{text.ReverseVideo}.Sprint(label)
+		}
+		columns = append(columns, label)
+	}
+
+	header := []string{
+		fmt.Sprintf("%s: %d repositories, sorted by %s (%s)", b.report.Name(), b.report.Count(), column, order),
+		"Sort by:" + strings.Join(columns, " "),
+		"",
+	}
+	footer := []string{"", "←/→: sort column  space: reverse order  ↑/↓: scroll  q: quit"}
+	if b.err != "" {
+		footer[0] = b.err
+	}
+
+	b.page = height - len(header) - len(footer)
+	if b.page < 1 {
+		b.page = 1
+	}
+	b.scroll(0)
+
+	table := b.lines()[b.offset:]
+	if len(table) > b.page {
+		table = table[:b.page]
+	}
+	lines := append(header, table...)
+	for len(lines) < height-len(footer) {
+		lines = append(lines, "")
+	}
+	return append(lines, footer...)
+}
@@ -0,0 +1,126 @@
+package tui
+
+import (
+	"unicode/utf8"
+)
+
+// KeyCode identifies the keys that aren't printable characters.
+type KeyCode int
+
+const (
+	// KeyRune is a printable character, see Key.Rune.
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyEsc
+	KeyBackspace
+	KeyTab
+	KeyShiftTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyCtrlC
+	// KeyUnknown is any other key, or escape sequence.
+	KeyUnknown
+)
+
+// Key is a key pressed in the terminal.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// escapeKeys are the keys sent as escape sequences, by their sequence
+// without the leading ESC. Both the CSI (`[`) and SS3 (`O`) forms of the
+// cursor keys are sent, depending on the terminal mode.
+var escapeKeys = map[string]KeyCode{
+	"[A":  KeyUp,
+	"[B":  KeyDown,
+	"[C":  KeyRight,
+	"[D":  KeyLeft,
+	"[H":  KeyHome,
+	"[F":  KeyEnd,
+	"[Z":  KeyShiftTab,
+	"OA":  KeyUp,
+	"OB":  KeyDown,
+	"OC":  KeyRight,
+	"OD":  KeyLeft,
+	"OH":  KeyHome,
+	"OF":  KeyEnd,
+	"[1~": KeyHome,
+	"[4~": KeyEnd,
+	"[5~": KeyPageUp,
+	"[6~": KeyPageDown,
+	"[7~": KeyHome,
+	"[8~": KeyEnd,
+}
+
+// parseKeys returns the keys in the input read from a terminal in raw mode.
+// A lone ESC is the escape key, as escape sequences are sent in a single
+// write by terminals.
+func parseKeys(b []byte) []Key {
+	var keys []Key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			n := escapeLen(b)
+			if n == 1 {
+				keys = append(keys, Key{Code: KeyEsc})
+			} else if code, ok := escapeKeys[string(b[1:n])]; ok {
+				keys = append(keys, Key{Code: code})
+			} else {
+				keys = append(keys, Key{Code: KeyUnknown})
+			}
+			b = b[n:]
+			continue
+		case c == '\r' || c == '\n':
+			keys = append(keys, Key{Code: KeyEnter})
+		case c == '\t':
+			keys = append(keys, Key{Code: KeyTab})
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, Key{Code: KeyBackspace})
+		case c == 0x03:
+			keys = append(keys, Key{Code: KeyCtrlC})
+		case c < 0x20:
+			keys = append(keys, Key{Code: KeyUnknown})
+		default:
+			r, n := utf8.DecodeRune(b)
+			keys = append(keys, Key{Code: KeyRune, Rune: r})
+			b = b[n:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// escapeLen returns the length of the escape sequence b starts with: up to
+// the final byte of a CSI sequence, the byte after an SS3 one, and only the
+// ESC otherwise.
+func escapeLen(b []byte) int {
+	if len(b) < 2 {
+		return 1
+	}
+	switch b[1] {
+	case 'O':
+		if len(b) < 3 {
+			return 1
+		}
+		return 3
+	case '[':
+		for i := 2; i < len(b); i++ {
+			// The final byte of a CSI sequence, parameters and
+			// intermediate bytes being lower.
+			if b[i] >= 0x40 && b[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(b)
+	default:
+		return 1
+	}
+}
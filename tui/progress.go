@@ -0,0 +1,156 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carlisia/ghinfo/analytics"
+)
+
+// barWidth is the width of the progress bar, in characters.
+const barWidth = 40
+
+// Progress shows the progress of a report run. It is both the progress
+// func of the report, see Report, and the observer of the GitHub client,
+// for the API calls and rate limit.
+type Progress struct {
+	title        string
+	since, maxID int
+	start        time.Time
+
+	mu        sync.Mutex
+	run       analytics.Progress
+	calls     int
+	failed    int
+	retries   int
+	rateLimit int
+	canceled  bool
+	cancel    func()
+}
+
+// NewProgress returns the progress of the report named title, run on the
+// repositories with IDs in (since, maxID].
+func NewProgress(title string, since, maxID int) *Progress {
+	return &Progress{title: title, since: since, maxID: maxID, start: time.Now(), rateLimit: -1}
+}
+
+// Watch shows the progress of run until it returns, and returns its error.
+// The context of run is canceled when the user quits.
+func (t *Terminal) Watch(ctx context.Context, p *Progress, run func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	p.mu.Lock()
+	p.cancel = cancel
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(done)
+		err = run(ctx)
+	}()
+
+	if uiErr := t.run(p, done); uiErr != nil {
+		cancel()
+		<-done
+		return uiErr
+	}
+	return err
+}
+
+// Report updates the progress of the run, see analytics.Watchable.
+func (p *Progress) Report(run analytics.Progress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.run = run
+}
+
+// ObserveRequest counts the API calls, and the failed ones.
+func (p *Progress) ObserveRequest(_ string, status int, _ time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if status == 0 || status >= 400 {
+		p.failed++
+	}
+}
+
+// ObserveRetry counts the retried API calls.
+func (p *Progress) ObserveRetry(string, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.retries++
+}
+
+// ObserveRateLimit keeps the remaining requests of the rate limit.
+func (p *Progress) ObserveRateLimit(remaining int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rateLimit = remaining
+}
+
+// update cancels the run when the user quits. The view is done with once
+// the run returns.
+func (p *Progress) update(k Key) bool {
+	if k.Code == KeyEsc || k.Code == KeyCtrlC || k.Code == KeyRune && k.Rune == 'q' {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.canceled = true
+		if p.cancel != nil {
+			p.cancel()
+		}
+	}
+	return false
+}
+
+func (p *Progress) view(width, height int) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The listing goes through the ID range in order, and the workers
+	// follow it.
+	var listed float64
+	if p.run.LastID > p.since && p.maxID > p.since {
+		listed = float64(p.run.LastID-p.since) / float64(p.maxID-p.since)
+	}
+	var enriched float64
+	if p.run.Listed > 0 {
+		enriched = listed * float64(p.run.Enriched+p.run.EnrichErrors) / float64(p.run.Listed)
+	}
+
+	rateLimit := "unknown"
+	if p.rateLimit >= 0 {
+		rateLimit = fmt.Sprintf("%d requests left", p.rateLimit)
+	}
+	help := "q: cancel"
+	if p.canceled {
+		help = "Canceling..."
+	}
+
+	return []string{
+		fmt.Sprintf("We are retrieving data for your %s...", p.title),
+		"",
+		bar(enriched) + fmt.Sprintf(" %3.0f%%", enriched*100),
+		"",
+		fmt.Sprintf("Repository IDs: %d to %d", p.since+1, p.maxID),
+		fmt.Sprintf("Pages listed:   %d", p.run.Pages),
+		fmt.Sprintf("Repositories:   %d listed, %d retrieved, %d failed", p.run.Listed, p.run.Enriched, p.run.EnrichErrors),
+		fmt.Sprintf("API calls:      %d, %d failed, %d retried", p.calls, p.failed, p.retries),
+		fmt.Sprintf("Rate limit:     %s", rateLimit),
+		fmt.Sprintf("Elapsed:        %s", time.Since(p.start).Round(time.Second)),
+		"",
+		help,
+	}
+}
+
+// bar draws a progress bar filled up to done, from 0 to 1.
+func bar(done float64) string {
+	if done > 1 {
+		done = 1
+	}
+	filled := int(done * barWidth)
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled) + "]"
+}
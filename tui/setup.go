@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jedib0t/go-pretty/text"
+
+	"github.com/carlisia/ghinfo/analytics"
+)
+
+// The fields of the setup form, in order.
+const (
+	reportField = iota
+	sinceField
+	maxIDField
+)
+
+// Setup is the form choosing the report to run, and the ID range of the
+// repositories it is run on.
+type Setup struct {
+	// Reports are the descriptions of the reports to choose from.
+	Reports []string
+	// Report is the index of the chosen report.
+	Report int
+	// Since and MaxID are the ID range, once the form is submitted.
+	Since, MaxID int
+
+	focus    int
+	since    string
+	maxID    string
+	err      string
+	canceled bool
+}
+
+// NewSetup returns a form to choose one of the reports, with the ID range
+// filled in with since and maxID.
+func NewSetup(reports []string, since, maxID int) *Setup {
+	return &Setup{
+		Reports: reports,
+		since:   strconv.Itoa(since),
+		maxID:   strconv.Itoa(maxID),
+	}
+}
+
+// Setup shows the form until it is submitted, and returns false when it
+// is canceled instead.
+func (t *Terminal) Setup(s *Setup) (bool, error) {
+	if err := t.run(s, nil); err != nil {
+		return false, err
+	}
+	return !s.canceled, nil
+}
+
+func (s *Setup) update(k Key) bool {
+	s.err = ""
+	switch k.Code {
+	case KeyEsc, KeyCtrlC:
+		s.canceled = true
+		return true
+	case KeyShiftTab:
+		s.move(-1)
+		return false
+	case KeyTab:
+		s.move(1)
+		return false
+	case KeyEnter:
+		if s.focus < maxIDField {
+			s.move(1)
+			return false
+		}
+		return s.submit()
+	}
+
+	if s.focus == reportField {
+		switch {
+		case k.Code == KeyUp && s.Report > 0:
+			s.Report--
+		case k.Code == KeyDown && s.Report < len(s.Reports)-1:
+			s.Report++
+		case k.Code == KeyRune && k.Rune == 'q':
+			s.canceled = true
+			return true
+		case k.Code == KeyRune && k.Rune >= '1' && int(k.Rune-'1') < len(s.Reports):
+			s.Report = int(k.Rune - '1')
+		}
+		return false
+	}
+
+	field := &s.since
+	if s.focus == maxIDField {
+		field = &s.maxID
+	}
+	switch {
+	case k.Code == KeyUp:
+		s.move(-1)
+	case k.Code == KeyDown:
+		s.move(1)
+	case k.Code == KeyBackspace && len(*field) > 0:
+		*field = (*field)[:len(*field)-1]
+	case k.Code == KeyRune && k.Rune >= '0' && k.Rune <= '9':
+		*field += string(k.Rune)
+	}
+	return false
+}
+
+func (s *Setup) move(by int) {
+	s.focus = (s.focus + by + maxIDField + 1) % (maxIDField + 1)
+}
+
+// submit checks the ID range, and returns whether the form is done.
+func (s *Setup) submit() bool {
+	since, err := strconv.Atoi(s.since)
+	if err != nil {
+		s.err, s.focus = "Please enter the min ID.", sinceField
+		return false
+	}
+	maxID, err := strconv.Atoi(s.maxID)
+	if err != nil {
+		s.err, s.focus = "Please enter the max ID.", maxIDField
+		return false
+	}
+	if err := analytics.ValidateIDRange(since, maxID); err != nil {
+		s.err = "Unfortunately " + err.Error() + "."
+		return false
+	}
+
+	s.Since, s.MaxID = since, maxID
+	return true
+}
+
+func (s *Setup) view(width, height int) []string {
+	lines := []string{
+		"Welcome! 🌞 Please choose a report kind...",
+		"",
+	}
+	for i, report := range s.Reports {
+		line := fmt.Sprintf("  %d. %s", i+1, report)
+		if i == s.Report {
+			line = "> " + line[2:]
+			if s.focus == reportField {
+				line = text.Colors{text.ReverseVideo}.Sprint(line)
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "",
+		"What is the Min ID? "+s.input(sinceField, s.since),
+		"What is the Max ID? "+s.input(maxIDField, s.maxID),
+		"",
+	)
+	if s.err != "" {
+		lines = append(lines, s.err, "")
+	}
+	return append(lines, "↑/↓: choose  tab: next field  enter: run the report  esc: quit")
+}
+
+// input formats the value of a field, with a cursor when it has the focus.
+func (s *Setup) input(field int, value string) string {
+	if s.focus != field {
+		return value
+	}
+	return value + text.Colors{text.ReverseVideo}.Sprint(" ")
+}
@@ -0,0 +1,162 @@
+// Package tui is the full screen terminal UI of the interactive mode: a
+// form to choose the report, the progress of its run, and its table, which
+// can be sorted again without running it, drawn with ANSI escape sequences.
+package tui
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/text"
+	"golang.org/x/term"
+)
+
+// The ANSI escape sequences used to draw the screen.
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	hideCursor     = "\x1b[?25l"
+	showCursor     = "\x1b[?25h"
+	cursorHome     = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+)
+
+// refresh is how often the screen is redrawn when no key is pressed, e.g.
+// to show the progress of a run, or fit a resized terminal.
+const refresh = 100 * time.Millisecond
+
+// ErrNotTerminal is returned by Open when the input or the output isn't a
+// terminal.
+var ErrNotTerminal = errors.New("not a terminal")
+
+// IsTerminal returns whether both in and out are terminals, which a
+// Terminal can be opened on.
+func IsTerminal(in, out *os.File) bool {
+	return term.IsTerminal(int(in.Fd())) && term.IsTerminal(int(out.Fd()))
+}
+
+// Terminal is a full screen terminal UI: the input is read key by key, and
+// the views are drawn on the alternate screen, which is left along with the
+// raw mode when it is closed, restoring the screen as it was.
+type Terminal struct {
+	in    *os.File
+	out   *os.File
+	state *term.State
+	keys  chan Key
+	// last is the last frame drawn, not to draw it again.
+	last string
+}
+
+// Open switches the terminal of in and out to raw mode and the alternate
+// screen.
+func Open(in, out *os.File) (*Terminal, error) {
+	if !IsTerminal(in, out) {
+		return nil, ErrNotTerminal
+	}
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Terminal{in: in, out: out, state: state, keys: make(chan Key, 16)}
+	if _, err := io.WriteString(out, enterAltScreen+hideCursor); err != nil {
+		term.Restore(int(in.Fd()), state)
+		return nil, err
+	}
+	go t.readKeys()
+	return t, nil
+}
+
+// Close restores the screen and the mode of the terminal.
+func (t *Terminal) Close() error {
+	io.WriteString(t.out, showCursor+leaveAltScreen)
+	return term.Restore(int(t.in.Fd()), t.state)
+}
+
+// readKeys sends the keys read from the input, until it can't be read
+// anymore.
+func (t *Terminal) readKeys() {
+	defer close(t.keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := t.in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			t.keys <- k
+		}
+	}
+}
+
+// model is a view of the terminal UI.
+type model interface {
+	// update handles a key, and returns whether the view is done with.
+	update(Key) bool
+	// view returns the lines of the screen, which is width columns wide
+	// and height lines high.
+	view(width, height int) []string
+}
+
+// run draws the model, and passes it the keys pressed, until it is done
+// with or done is closed.
+func (t *Terminal) run(m model, done <-chan struct{}) error {
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		if err := t.draw(m); err != nil {
+			return err
+		}
+		select {
+		case k, ok := <-t.keys:
+			if !ok {
+				return io.ErrUnexpectedEOF
+			}
+			if m.update(k) {
+				return t.draw(m)
+			}
+		case <-ticker.C:
+		case <-done:
+			return t.draw(m)
+		}
+	}
+}
+
+// draw draws the view of the model over the previous one, cutting the
+// lines that don't fit the screen.
+func (t *Terminal) draw(m model) error {
+	width, height, err := term.GetSize(int(t.out.Fd()))
+	if err != nil || width == 0 || height == 0 {
+		// Unknown, e.g. the size of a pseudo terminal that hasn't
+		// been set.
+		width, height = 80, 24
+	}
+
+	lines := m.view(width, height)
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+
+	var frame strings.Builder
+	frame.WriteString(cursorHome)
+	for i, line := range lines {
+		frame.WriteString(text.Trim(line, width))
+		frame.WriteString(clearLine)
+		if i < len(lines)-1 {
+			frame.WriteString("\r\n")
+		}
+	}
+	frame.WriteString(clearBelow)
+
+	if frame.String() == t.last {
+		return nil
+	}
+	t.last = frame.String()
+	_, err = io.WriteString(t.out, t.last)
+	return err
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/carlisia/ghinfo/analytics"
+	"github.com/carlisia/ghinfo/github/githubtest"
+)
+
+func Test_parseKeys(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []Key
+	}{
+		{
+			name:     "runes",
+			input:    "q1é",
+			expected: []Key{{Code: KeyRune, Rune: 'q'}, {Code: KeyRune, Rune: '1'}, {Code: KeyRune, Rune: 'é'}},
+		},
+		{
+			name:     "control keys",
+			input:    "\r\t\x7f\x03",
+			expected: []Key{{Code: KeyEnter}, {Code: KeyTab}, {Code: KeyBackspace}, {Code: KeyCtrlC}},
+		},
+		{
+			name:     "cursor keys",
+			input:    "\x1b[A\x1b[B\x1bOC\x1b[D\x1b[Z",
+			expected: []Key{{Code: KeyUp}, {Code: KeyDown}, {Code: KeyRight}, {Code: KeyLeft}, {Code: KeyShiftTab}},
+		},
+		{
+			name:     "pages",
+			input:    "\x1b[5~\x1b[6~",
+			expected: []Key{{Code: KeyPageUp}, {Code: KeyPageDown}},
+		},
+		{
+			name:     "escape",
+			input:    "\x1b",
+			expected: []Key{{Code: KeyEsc}},
+		},
+		{
+			name:     "unknown sequence",
+			input:    "\x1b[1;5Ck",
+			expected: []Key{{Code: KeyUnknown}, {Code: KeyRune, Rune: 'k'}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, parseKeys([]byte(tc.input)))
+		})
+	}
+}
+
+func typeKeys(m model, input string) bool {
+	for _, k := range parseKeys([]byte(input)) {
+		if m.update(k) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSetup(t *testing.T) {
+	s := NewSetup([]string{"stars", "licenses", "owners"}, 100, 200)
+
+	// Picks the second report, then clears the max ID.
+	require.False(t, typeKeys(s, "\x1b[B\x1b[B\x1b[A\t\t\x7f\x7f\x7f"))
+	require.Equal(t, 1, s.Report)
+	require.False(t, typeKeys(s, "\r"))
+	require.Equal(t, "Please enter the max ID.", s.err)
+
+	require.False(t, typeKeys(s, "9x9\r"))
+	require.Contains(t, s.err, "cannot be smaller than the `since` value")
+
+	require.True(t, typeKeys(s, "\x7f\x7f250\r"))
+	require.False(t, s.canceled)
+	require.Equal(t, 100, s.Since)
+	require.Equal(t, 250, s.MaxID)
+
+	s = NewSetup([]string{"stars"}, 100, 200)
+	require.True(t, typeKeys(s, "q"))
+	require.True(t, s.canceled)
+}
+
+func TestProgress(t *testing.T) {
+	p := NewProgress("StarGazers Report", 1000, 1100)
+	p.Report(analytics.Progress{Pages: 2, Listed: 40, LastID: 1050, Enriched: 19, EnrichErrors: 1})
+	p.ObserveRequest("/repositories", 200, 0)
+	p.ObserveRequest("/repos/:owner/:repo", 404, 0)
+	p.ObserveRetry("/repos/:owner/:repo", "server_error")
+	p.ObserveRateLimit(4998)
+
+	view := strings.Join(p.view(80, 24), "\n")
+	require.Contains(t, view, "Repository IDs: 1001 to 1100")
+	require.Contains(t, view, "░]  25%")
+	require.Contains(t, view, "Pages listed:   2")
+	require.Contains(t, view, "Repositories:   40 listed, 19 retrieved, 1 failed")
+	require.Contains(t, view, "API calls:      2, 1 failed, 1 retried")
+	require.Contains(t, view, "Rate limit:     4998 requests left")
+
+	canceled := false
+	p.cancel = func() { canceled = true }
+	require.False(t, typeKeys(p, "q"))
+	require.True(t, canceled)
+	require.Contains(t, strings.Join(p.view(80, 24), "\n"), "Canceling...")
+}
+
+func TestBrowser(t *testing.T) {
+	server := githubtest.NewServer(githubtest.Generate(githubtest.Dataset{Seed: 3, Count: 30, FirstID: 1000, MaxGap: 3, Owners: 20}))
+	defer server.Close()
+	repos := server.Repos()
+
+	report, err := analytics.NewReport(analytics.OwnerReportType, analytics.ParamOptions{
+		Asc:   true,
+		Since: 999,
+		MaxID: repos[len(repos)-1].ID,
+	})
+	require.NoError(t, err)
+	report.(analytics.Watchable).SetProgress(func(analytics.Progress) {})
+	require.NoError(t, report.Run(context.Background(), server.Github()))
+
+	b, err := NewBrowser(report)
+	require.NoError(t, err)
+	sorted := func() string {
+		column, asc := report.(analytics.Sortable).SortedBy()
+		if asc {
+			return column + " asc"
+		}
+		return column + " desc"
+	}
+
+	view := b.view(100, 10)
+	require.Len(t, view, 10)
+	require.Equal(t, "Owners Report: 30 repositories, sorted by owner (asc)", view[0])
+	require.Contains(t, view[1], "1 owner")
+	require.Contains(t, view[4], "owner")
+
+	require.False(t, typeKeys(b, "\x1b[C\x1b[C"))
+	require.Equal(t, "repos asc", sorted())
+	require.False(t, typeKeys(b, " "))
+	require.Equal(t, "repos desc", sorted())
+	require.False(t, typeKeys(b, "1\x1b[D"))
+	require.Equal(t, "stars desc", sorted())
+
+	// Scrolls down to the footer of the table, but not further.
+	require.False(t, typeKeys(b, "\x1b[6~\x1b[6~\x1b[6~\x1b[6~\x1b[6~\x1b[6~"))
+	view = b.view(100, 10)
+	require.Contains(t, view[7], "╰")
+	require.False(t, typeKeys(b, "\x1b[H"))
+	require.Equal(t, 0, b.offset)
+
+	require.True(t, typeKeys(b, "q"))
+}